@@ -55,7 +55,12 @@ func newFxLoggerOption(verbose bool) fx.Option {
 // runCLI builds and executes the root CLI command.
 func runCLI(reg ports.Registry) error {
 	root := cli.NewRootCmd(reg)
-	return root.Execute()
+	if err := root.Execute(); err != nil {
+		// Errors are silenced in cobra so usage isn't repeated; report them once here.
+		root.PrintErrln(root.ErrPrefix(), err.Error())
+		return err
+	}
+	return nil
 }
 
 func main() {
@@ -3,6 +3,7 @@ module github.com/nduyhai/gocraft
 go 1.24.1
 
 require (
+	github.com/pelletier/go-toml/v2 v2.2.3
 	github.com/spf13/cobra v1.9.1
 	go.uber.org/fx v1.24.0
 	golang.org/x/mod v0.27.0
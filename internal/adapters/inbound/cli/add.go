@@ -6,22 +6,29 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
-	configfileeditor "github.com/nduyhai/gocraft/internal/adapters/outbound/config/fileeditor"
-	"github.com/nduyhai/gocraft/internal/adapters/outbound/context/contextimpl"
-	amfileeditor "github.com/nduyhai/gocraft/internal/adapters/outbound/di/fileeditor"
-	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/oswriter"
-	gomodfileeditor "github.com/nduyhai/gocraft/internal/adapters/outbound/gomod/fileeditor"
-	"github.com/nduyhai/gocraft/internal/adapters/outbound/rendering/texttmpl"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/memfs"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/osfs"
+	goworkfileeditor "github.com/nduyhai/gocraft/internal/adapters/outbound/gowork/fileeditor"
+	hookexec "github.com/nduyhai/gocraft/internal/adapters/outbound/hooks/exec"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/templates/embed_repo"
 	"github.com/nduyhai/gocraft/internal/core/ports"
 	"github.com/nduyhai/gocraft/internal/core/usecase"
 	"github.com/spf13/cobra"
 )
 
-// newAddCmd creates the `add` command which applies one or more modules to the current project directory.
+// newAddCmd creates the `add` command which applies one or more modules to the current project
+// directory, or to --project inside a workspace.
 func newAddCmd(reg ports.Registry) *cobra.Command {
-	var set []string
+	var (
+		set     []string
+		dryRun  bool
+		hooks   hookFlags
+		gen     genFlags
+		project string
+	)
 	cmd := &cobra.Command{
 		Use:   "add <module>...",
 		Short: "Apply module(s) to the current project",
@@ -31,22 +38,34 @@ func newAddCmd(reg ports.Registry) *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("getwd: %w", err)
 			}
+			root := cwd
+			if project != "" {
+				root = filepath.Join(cwd, project)
+				if fi, err := os.Stat(filepath.Join(root, "go.mod")); err != nil || fi.IsDir() {
+					return fmt.Errorf("--project %s: no go.mod in %s", project, root)
+				}
+			}
+
+			cfg, err := loadSettings()
+			if err != nil {
+				return err
+			}
+			hooks.applySettings(cmd, cfg.Hooks)
 
 			// Determine Name and Module for rendering context
-			name := filepath.Base(cwd)
-			modulePath, err := readModulePath(filepath.Join(cwd, "go.mod"))
+			name := filepath.Base(root)
+			modulePath, err := readModulePath(filepath.Join(root, "go.mod"))
 			if err != nil {
+				// At a workspace root, point at the modules instead of generating a new project here
+				if uses := workspaceUses(root); len(uses) > 0 {
+					return fmt.Errorf("%s is a workspace root; choose a module with --project (%s)", root, strings.Join(uses, ", "))
+				}
 				// Fallback to a sensible default if go.mod is missing
-				modulePath = fmt.Sprintf("github.com/you/%s", name)
+				if modulePath = cfg.Module(name); modulePath == "" {
+					modulePath = fmt.Sprintf("github.com/you/%s", name)
+				}
 			}
 
-			// Outbound collaborators
-			renderer := texttmpl.New()
-			writer := oswriter.New()
-			gomod := gomodfileeditor.New(cwd)
-			adaptersEditor := amfileeditor.New(cwd)
-			cfgEditor := configfileeditor.New(cwd)
-
 			// Build context
 			vals := map[string]any{
 				"Name":   name,
@@ -55,21 +74,69 @@ func newAddCmd(reg ports.Registry) *cobra.Command {
 			if len(set) > 0 {
 				mergeSetsInto(vals, set)
 			}
-			ctx := contextimpl.New(cwd, writer, renderer, gomod, adaptersEditor, cfgEditor, vals)
+			applySettingsValues(vals, cfg)
+			// Stage all edits in memory on top of the project; commit only if every module succeeds
+			disk := osfs.New()
+			stage := memfs.NewOverlay(disk)
+			opts, err := gen.options(cmd, cfg)
+			if err != nil {
+				return err
+			}
+			ctx := newModuleCtx(stage, root, vals, embed_repo.New(reg, templateDirs(root, cfg)), opts)
 
 			// Use usecase to apply modules with injected registry
 			uc := usecase.ApplyModules{Registry: reg}
 			if err := uc.Execute(ctx, args...); err != nil {
 				return err
 			}
+			printVersionReport(cmd.ErrOrStderr(), ctx.GoMod())
+			hook := hooks.pipeline(ctx.Hooks(), nil, hookexec.GitCommit("chore: add "+strings.Join(args, ", "), touchedPaths(root, stage.Changes())...))
+			if dryRun {
+				printChanges(cmd.OutOrStdout(), stage.Changes())
+				printPlannedSteps(cmd.OutOrStdout(), hook)
+				return nil
+			}
+			if err := stage.Commit(disk); err != nil {
+				return fmt.Errorf("write project: %w", err)
+			}
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Applied modules: %s\n", strings.Join(args, ", "))
-			return nil
+			return runHooks(cmd, cmd.OutOrStdout(), hook, root)
 		},
 	}
 	cmd.Flags().StringSliceVar(&set, "set", nil, "Set template values (key=value). Supports dot paths, e.g., --set gorm.driver=postgres")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the files that would be written or modified without touching the disk")
+	gen.register(cmd)
+	cmd.Flags().StringVar(&project, "project", "", "Project directory relative to the current directory, e.g. services/billing from a go.work root")
+	hooks.register(cmd, "Commit the changes to the project's git repository")
 	return cmd
 }
 
+// touchedPaths returns the paths a generation changed relative to root (where the git steps
+// run), plus go.mod and go.sum which the post-generation hooks may rewrite, so `add --git`
+// commits nothing else.
+func touchedPaths(root string, changes []memfs.Change) []string {
+	paths := []string{"go.mod", "go.sum"}
+	for _, c := range changes {
+		rel, err := filepath.Rel(root, c.Path)
+		if err != nil {
+			continue
+		}
+		if rel = filepath.ToSlash(rel); !slices.Contains(paths, rel) {
+			paths = append(paths, rel)
+		}
+	}
+	return paths
+}
+
+// workspaceUses returns the use directives of a go.work located directly in dir.
+func workspaceUses(dir string) []string {
+	uses, err := goworkfileeditor.NewFS(osfs.New(), filepath.Join(dir, goworkfileeditor.FileName)).Uses()
+	if err != nil {
+		return nil
+	}
+	return uses
+}
+
 // readModulePath reads the module path from a go.mod file. Returns an error if the file
 // cannot be read or the module line is not found.
 func readModulePath(goModPath string) (string, error) {
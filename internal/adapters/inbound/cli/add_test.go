@@ -0,0 +1,48 @@
+package cli_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAdd_ExistingProjectSkipsBase(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	gocraft(t, "new", "demo", "-m", "example.com/demo")
+	t.Chdir("demo")
+
+	// Local edits platform:base would overwrite if it ran again
+	cfg := filepath.Join("config", "config.yml")
+	b, err := os.ReadFile(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := strings.Replace(string(b), "level: info", "level: debug", 1)
+	if err := os.WriteFile(cfg, []byte(edited), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	envModule := filepath.Join("internal", "platform", "env", "module.go")
+	before, err := os.ReadFile(envModule)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gocraft(t, "add", "http:chi")
+
+	if b, _ := os.ReadFile(cfg); !strings.Contains(string(b), "level: debug") || !strings.Contains(string(b), "addr:") {
+		t.Errorf("config.yml lost the edit or the http:chi defaults:\n%s", b)
+	}
+	root, _ := os.ReadFile(filepath.Join("internal", "platform", "di", "root.go"))
+	if !strings.Contains(string(root), "logger.Module()") || !strings.Contains(string(root), "httpchi.Module()") {
+		t.Errorf("DI root lost a module:\n%s", root)
+	}
+	if after, _ := os.ReadFile(envModule); string(after) != string(before) {
+		t.Errorf("env module rewritten:\n%s", after)
+	}
+	if _, err := os.Stat(filepath.Join("config", "config.local.yml")); err != nil {
+		t.Errorf("local profile: %v", err)
+	}
+}
@@ -0,0 +1,310 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	configeditor "github.com/nduyhai/gocraft/internal/adapters/outbound/config/fileeditor"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/config/schema"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/oswriter"
+	gomodfileeditor "github.com/nduyhai/gocraft/internal/adapters/outbound/gomod/fileeditor"
+	"github.com/nduyhai/gocraft/internal/core/ports"
+	"github.com/nduyhai/gocraft/internal/platform/paths"
+	"github.com/nduyhai/gocraft/internal/platform/settings"
+	"github.com/spf13/cobra"
+)
+
+// newConfigCmd creates the `config` command group for gocraft's user and project settings.
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect and change gocraft settings (~/.config/gocraft/config.yaml and .gocraft.yaml), validate project config",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(newConfigListCmd())
+	cmd.AddCommand(newConfigGetCmd())
+	cmd.AddCommand(newConfigSetCmd())
+	cmd.AddCommand(newConfigValidateCmd())
+	return cmd
+}
+
+// newConfigListCmd creates the `config list` command which prints the effective settings and
+// the file each one comes from.
+func newConfigListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List effective settings and where they are set",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := settings.Load(".")
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+			for _, e := range cfg.Entries() {
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", e.Key, e.Value, e.Source)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			for _, l := range []settings.Layer{cfg.User, cfg.Project} {
+				if l.Exists() {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s: %s\n", l.Scope, l.Path)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// newConfigGetCmd creates the `config get` command which prints one setting, or every setting
+// below a prefix such as values.
+func newConfigGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the effective value of a setting",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := settings.Load(".")
+			if err != nil {
+				return err
+			}
+			entries := cfg.Get(args[0])
+			if len(entries) == 0 {
+				return fmt.Errorf("%s is not set", args[0])
+			}
+			if len(entries) == 1 && entries[0].Key == args[0] {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), entries[0].Value)
+				return nil
+			}
+			for _, e := range entries {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s=%s\n", e.Key, e.Value)
+			}
+			return nil
+		},
+	}
+}
+
+// newConfigSetCmd creates the `config set` command which writes a setting to the project file,
+// or to the user file with --user.
+func newConfigSetCmd() *cobra.Command {
+	var user bool
+	cmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Set a setting in .gocraft.yaml (or the user config with --user)",
+		Long: `Set a setting in the nearest .gocraft.yaml (created in the current directory when missing),
+or in ~/.config/gocraft/config.yaml with --user.
+
+Keys: module_prefix, modules (comma-separated), templates, conflicts (error|skip|overwrite),
+versions (minimum|pinned|latest-cached), hooks.git, hooks.tidy, hooks.verify and values.<path>.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, value := args[0], args[1]
+			switch key {
+			case "conflicts":
+				if _, err := oswriter.ParseConflict(value); err != nil {
+					return err
+				}
+			case "versions":
+				if _, err := gomodfileeditor.ParsePolicy(value); err != nil {
+					return err
+				}
+			}
+			cfg, err := settings.Load(".")
+			if err != nil {
+				return err
+			}
+			layer := cfg.Project
+			if user {
+				if cfg.User.Path == "" {
+					return fmt.Errorf("no user config directory")
+				}
+				layer = cfg.User
+			}
+			if err := settings.Set(layer.Path, key, value); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Set %s in %s\n", key, layer.Path)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&user, "user", false, "Write to the user config (~/.config/gocraft/config.yaml) instead of the project")
+	return cmd
+}
+
+// newConfigValidateCmd creates the `config validate` command which checks a generated project's
+// config files against the schema its modules declared (config/schema.json).
+func newConfigValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate [file]",
+		Short: "Check the project's config files against the installed modules' schema",
+		Long: `Check config files against config/schema.json, the JSON Schema that gocraft merges from the
+installed modules (for example server.http.addr must be host:port and gorm.driver one of postgres,
+mysql or sqlite). Without a file, config/config.yml (or .toml, .json) and its profile overlays
+are checked, or .env.example in env-only projects.
+The schema is read from the directory of each file, or its config/ subdirectory.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			files := args
+			if len(files) == 0 {
+				var err error
+				if files, err = projectConfigFiles(); err != nil {
+					return err
+				}
+			}
+			problems := 0
+			for _, f := range files {
+				issues, err := validateConfigFile(f)
+				if err != nil {
+					return err
+				}
+				if len(issues) == 0 {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "ok      %s\n", f)
+					continue
+				}
+				problems += len(issues)
+				for _, i := range issues {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "invalid %s: %s\n", f, i)
+				}
+			}
+			if problems > 0 {
+				return fmt.Errorf("%d problem(s) in config", problems)
+			}
+			return nil
+		},
+	}
+}
+
+// projectConfigFiles returns the config files of the project in the current directory, in the
+// format it uses: the base config first, then the overlays.
+func projectConfigFiles() ([]string, error) {
+	format := configeditor.New(".").Format()
+	if format == ports.ConfigEnv {
+		return []string{configeditor.EnvExample}, nil
+	}
+	ext := format
+	if format == ports.ConfigYAML {
+		ext = "yml"
+	}
+	files, err := filepath.Glob(filepath.Join("config", "config*."+ext))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no config/config*.%s in the current directory", ext)
+	}
+	sort.SliceStable(files, func(i, j int) bool { return filepath.Base(files[i]) == "config."+ext })
+	return files, nil
+}
+
+// validateConfigFile checks one config file, in any supported format, against the schema.json
+// next to it (or in config/ for .env.example).
+func validateConfigFile(path string) ([]schema.Issue, error) {
+	sp := filepath.Join(filepath.Dir(path), filepath.Base(configeditor.SchemaFile))
+	if _, err := os.Stat(sp); err != nil {
+		sp = filepath.Join(filepath.Dir(path), filepath.FromSlash(configeditor.SchemaFile))
+	}
+	b, err := os.ReadFile(sp)
+	if err != nil {
+		return nil, fmt.Errorf("no config schema: %w (it is written when modules are added)", err)
+	}
+	sch, err := schema.Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", sp, err)
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := configeditor.Decode(path, src)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return sch.Validate(doc), nil
+}
+
+// genFlags are the generation flags new, add, service add and serve share with gocraft settings.
+type genFlags struct {
+	versions string
+	conflict string
+}
+
+func (f *genFlags) register(cmd *cobra.Command) {
+	versionPolicyFlag(cmd, &f.versions)
+	cmd.Flags().StringVar(&f.conflict, "conflict", "", "What to do with files that already exist: error (default), skip or overwrite")
+}
+
+// options resolves the flags, falling back to settings for flags that were not given.
+func (f genFlags) options(cmd *cobra.Command, s settings.Settings) (ctxOptions, error) {
+	versions, conflict := f.versions, f.conflict
+	if !cmd.Flags().Changed("versions") {
+		versions = s.Versions
+	}
+	if !cmd.Flags().Changed("conflict") {
+		conflict = s.Conflicts
+	}
+	policy, err := gomodfileeditor.ParsePolicy(versions)
+	if err != nil {
+		return ctxOptions{}, err
+	}
+	c, err := oswriter.ParseConflict(conflict)
+	if err != nil {
+		return ctxOptions{}, err
+	}
+	return ctxOptions{policy: policy, conflict: c}, nil
+}
+
+// loadSettings loads the settings that apply to commands run from the working directory.
+func loadSettings() (settings.Settings, error) {
+	cfg, err := settings.Load(".")
+	if err != nil {
+		return settings.Settings{}, err
+	}
+	return cfg.Settings, nil
+}
+
+// templateDirs returns the template override roots for projectDir, led by the settings' templates.
+func templateDirs(projectDir string, s settings.Settings) []string {
+	dirs := paths.TemplateSearchDirs(projectDir)
+	if s.Templates != "" {
+		dirs = append([]string{s.Templates}, dirs...)
+	}
+	return dirs
+}
+
+// uniqueModules concatenates module lists in order, skipping duplicates.
+func uniqueModules(lists ...[]string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, l := range lists {
+		for _, m := range l {
+			if !seen[m] {
+				seen[m] = true
+				out = append(out, m)
+			}
+		}
+	}
+	return out
+}
+
+// applySettingsValues fills values not set on the command line from the settings.
+func applySettingsValues(vals map[string]any, s settings.Settings) {
+	mergeMissingInto(vals, copyValues(s.Values))
+}
+
+func copyValues(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		if sub, ok := v.(map[string]any); ok {
+			v = copyValues(sub)
+		}
+		out[k] = v
+	}
+	return out
+}
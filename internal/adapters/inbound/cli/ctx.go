@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	configfileeditor "github.com/nduyhai/gocraft/internal/adapters/outbound/config/fileeditor"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/context/contextimpl"
+	amfileeditor "github.com/nduyhai/gocraft/internal/adapters/outbound/di/fileeditor"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/memfs"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/oswriter"
+	gomodfileeditor "github.com/nduyhai/gocraft/internal/adapters/outbound/gomod/fileeditor"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/rendering/texttmpl"
+	"github.com/nduyhai/gocraft/internal/core/ports"
+	"github.com/spf13/cobra"
+)
+
+// ctxOptions selects how go.mod require versions are chosen and how existing files are handled.
+type ctxOptions struct {
+	policy   gomodfileeditor.Policy
+	conflict oswriter.Conflict
+}
+
+// newModuleCtx wires the outbound collaborators that generate into root through fsys.
+func newModuleCtx(fsys ports.FileSystem, root string, vals map[string]any, repo ports.TemplateRepo, opts ctxOptions) *contextimpl.Ctx {
+	return contextimpl.New(
+		root,
+		oswriter.NewFS(fsys).WithConflict(opts.conflict),
+		texttmpl.New(),
+		gomodfileeditor.NewFS(fsys, root).WithPolicy(opts.policy),
+		amfileeditor.NewFS(fsys, root),
+		configfileeditor.NewFS(fsys, root).WithTemplates(repo),
+		vals,
+	).WithFiles(fsys).WithTemplates(repo)
+}
+
+// printChanges lists staged changes; used by --dry-run.
+func printChanges(w io.Writer, changes []memfs.Change) {
+	for _, c := range changes {
+		_, _ = fmt.Fprintf(w, "%-6s %s\n", c.Op, c.Path)
+	}
+}
+
+// printVersionReport lists go.mod requires added or bumped by modules and version warnings.
+func printVersionReport(w io.Writer, gm ports.GoModEditor) {
+	ed, ok := gm.(*gomodfileeditor.Editor)
+	if !ok {
+		return
+	}
+	for _, b := range ed.Bumps() {
+		if b.From == "" {
+			continue // plain additions are visible in go.mod
+		}
+		_, _ = fmt.Fprintf(w, "%-6s %s %s => %s (%s)\n", "bump", b.Path, b.From, b.To, b.Module)
+	}
+	for _, msg := range ed.Warnings() {
+		_, _ = fmt.Fprintf(w, "%-6s %s\n", "warn", msg)
+	}
+}
+
+// versionPolicyFlag registers --versions on cmd.
+func versionPolicyFlag(cmd *cobra.Command, p *string) {
+	cmd.Flags().StringVar(p, "versions", "", "Version policy for go.mod requires: minimum (default; keep the higher of existing and requested), pinned (use module versions as-is), latest-cached (newest version in the module cache or a file:// GOPROXY)")
+}
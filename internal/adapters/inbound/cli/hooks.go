@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/context/contextimpl"
+	gomodfileeditor "github.com/nduyhai/gocraft/internal/adapters/outbound/gomod/fileeditor"
+	hookexec "github.com/nduyhai/gocraft/internal/adapters/outbound/hooks/exec"
+	sourcemodule "github.com/nduyhai/gocraft/internal/adapters/outbound/modules/source"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/verify"
+	"github.com/nduyhai/gocraft/internal/core/ports"
+	"github.com/nduyhai/gocraft/internal/platform/settings"
+	"github.com/spf13/cobra"
+)
+
+// hookFlags holds the post-generation hook flags shared by `new` and `add`.
+type hookFlags struct {
+	git       bool
+	tidy      bool
+	verify    bool
+	noHooks   bool
+	skipHooks bool
+
+	// commands are post-generate commands from gocraft settings, run after the modules' hooks.
+	commands []settings.Command
+
+	// modDir is the go module tidy and verify run in, relative to the hook directory
+	// ("" for the project itself, "platform" for a monorepo).
+	modDir string
+}
+
+func (f *hookFlags) register(cmd *cobra.Command, gitUsage string) {
+	cmd.Flags().BoolVar(&f.git, "git", false, gitUsage)
+	cmd.Flags().BoolVar(&f.tidy, "tidy", false, "Run go mod tidy after generation (offline when modules are cached)")
+	cmd.Flags().BoolVar(&f.noHooks, "no-hooks", false, "Skip every post-generation step, including --git, --tidy and --verify")
+	cmd.Flags().BoolVar(&f.skipHooks, "skip-hooks", false, "Skip only the commands declared by modules, template sources and settings; --git, --tidy and --verify still run")
+}
+
+// applySettings takes the hook defaults from s for flags that were not given on cmd.
+func (f *hookFlags) applySettings(cmd *cobra.Command, s settings.Hooks) {
+	for _, b := range []struct {
+		flag string
+		dst  *bool
+		val  *bool
+	}{{"git", &f.git, s.Git}, {"tidy", &f.tidy, s.Tidy}, {"verify", &f.verify, s.Verify}} {
+		if b.val != nil && cmd.Flags().Lookup(b.flag) != nil && !cmd.Flags().Changed(b.flag) {
+			*b.dst = *b.val
+		}
+	}
+	f.commands = s.Run
+}
+
+// any reports whether a hook was requested.
+func (f hookFlags) any() bool { return f.git || f.tidy || f.verify }
+
+// pipeline builds the post-generation steps: tidy first so go.sum is part of the commit and
+// module hooks can run go tooling, then the modules' post-generate hooks in application order
+// and the commands from gocraft settings, then build/vet (which imply tidy), then git.
+// origins attributes verification failures to modules; it is only used with verify.
+func (f hookFlags) pipeline(moduleHooks []contextimpl.ModuleHook, origins verify.Origins, gitSteps []hookexec.Step) *hookexec.Hook {
+	if f.noHooks {
+		return hookexec.New()
+	}
+	var steps []hookexec.Step
+	if f.tidy || f.verify {
+		steps = append(steps, hookexec.Step{Name: "go mod tidy", Run: func(dir string) (string, error) {
+			return "", gomodfileeditor.New(filepath.Join(dir, f.modDir)).Tidy()
+		}})
+	}
+	if !f.skipHooks {
+		for _, mh := range moduleHooks {
+			steps = append(steps, hookexec.FromHook(mh.Module, mh.Hook))
+		}
+		for _, c := range f.commands {
+			steps = append(steps, hookexec.FromHook("config", ports.Hook{
+				Name: c.Name, Stage: ports.HookPostGenerate, Command: c.Run, Dir: c.Dir, Env: c.Env,
+			}))
+		}
+	}
+	if f.verify {
+		v := verify.New(origins)
+		steps = append(steps,
+			hookexec.Step{Name: "go build ./...", Run: f.inModDir(v.Build)},
+			hookexec.Step{Name: "go vet ./...", Run: f.inModDir(v.Vet)},
+		)
+	}
+	if f.git {
+		steps = append(steps, gitSteps...)
+	}
+	return hookexec.New(steps...)
+}
+
+// inModDir adapts a step function to run in the module directory below the hook directory.
+func (f hookFlags) inModDir(run func(dir string) (string, error)) func(dir string) (string, error) {
+	return func(dir string) (string, error) { return run(filepath.Join(dir, f.modDir)) }
+}
+
+// runHooks runs the hook in dir and prints each step's status, with output for failures
+// (and for every step with --verbose).
+func runHooks(cmd *cobra.Command, w io.Writer, hook *hookexec.Hook, dir string) error {
+	results, err := hook.Run(dir)
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	printStepResults(w, results, verbose)
+	if err != nil {
+		return fmt.Errorf("hook %w", err)
+	}
+	return nil
+}
+
+func printStepResults(w io.Writer, results []ports.StepResult, verbose bool) {
+	for _, r := range results {
+		_, _ = fmt.Fprintf(w, "%-7s %s\n", r.Status, r.Name)
+		out := r.Output
+		if r.Err != nil && out == "" {
+			out = r.Err.Error()
+		}
+		if (r.Status == ports.StepFailed || verbose) && out != "" {
+			_, _ = fmt.Fprintf(w, "        %s\n", strings.ReplaceAll(out, "\n", "\n        "))
+		}
+	}
+}
+
+// printPlannedSteps lists the steps a dry run would execute.
+func printPlannedSteps(w io.Writer, hook *hookexec.Hook) {
+	for _, s := range hook.Steps() {
+		_, _ = fmt.Fprintf(w, "%-6s %s\n", "hook", s.Name)
+	}
+}
+
+// confirmSourceHooks lists the commands a remote template source runs after generation and
+// asks before running them. Without a yes (e.g. no terminal to answer on) it refuses.
+func confirmSourceHooks(cmd *cobra.Command, from string, commands []sourcemodule.HookEntry) error {
+	w := cmd.ErrOrStderr()
+	_, _ = fmt.Fprintf(w, "Template source %s runs these commands after generation:\n", from)
+	for _, c := range commands {
+		line := hookexec.Command(c.Run[0], c.Run[1:]...).Name
+		if c.Dir != "" {
+			line += " (in " + c.Dir + ")"
+		}
+		if len(c.Env) > 0 {
+			line += " with " + strings.Join(c.Env, " ")
+		}
+		_, _ = fmt.Fprintf(w, "  %s\n", line)
+	}
+	_, _ = fmt.Fprint(w, "Run them? [y/N] ")
+	answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil {
+		_, _ = fmt.Fprintln(w)
+	}
+	if a := strings.ToLower(strings.TrimSpace(answer)); a == "y" || a == "yes" {
+		return nil
+	}
+	return fmt.Errorf("hooks of %s not confirmed: pass --trust to run them or --skip-hooks to skip them", from)
+}
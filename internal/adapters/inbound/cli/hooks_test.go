@@ -0,0 +1,82 @@
+package cli_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// templateSource writes a template source whose hook touches "hooked" in the project, committed
+// to a git repository so it can also be used as a remote (git+file://) source.
+func templateSource(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "kit")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := "name: kit\nhooks:\n  - run: [touch, hooked]\n"
+	if err := os.WriteFile(filepath.Join(dir, "gocraft.yaml"), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"-c", "user.name=t", "-c", "user.email=t@t", "commit", "-qm", "init"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestNew_ConfirmsRemoteSourceHooks(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	src := templateSource(t)
+	remote := "git+file://" + src
+
+	cases := []struct {
+		name   string
+		from   string
+		stdin  string
+		flags  []string
+		prompt bool
+		hooked bool
+	}{
+		{name: "declined", from: remote, stdin: "n\n", prompt: true},
+		{name: "confirmed", from: remote, stdin: "y\n", prompt: true, hooked: true},
+		{name: "trust", from: remote, flags: []string{"--trust"}, hooked: true},
+		{name: "no-hooks", from: remote, flags: []string{"--no-hooks"}},
+		{name: "skip-hooks", from: remote, flags: []string{"--skip-hooks"}},
+		{name: "local", from: src, hooked: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			args := append([]string{"new", "demo", "-m", "example.com/demo", "--from", c.from}, c.flags...)
+			out, err := gocraftIn(c.stdin, args...)
+			if prompted := strings.Contains(out, "Run them?"); prompted != c.prompt {
+				t.Errorf("prompted = %v, want %v:\n%s", prompted, c.prompt, out)
+			}
+			if c.name == "declined" {
+				if err == nil || !strings.Contains(err.Error(), "not confirmed") {
+					t.Fatalf("err = %v, want hooks not confirmed", err)
+				}
+				if _, err := os.Stat("demo"); err == nil {
+					t.Error("project written although the hooks were declined")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("gocraft %s: %v\n%s", strings.Join(args, " "), err, out)
+			}
+			_, statErr := os.Stat(filepath.Join("demo", "hooked"))
+			if hooked := statErr == nil; hooked != c.hooked {
+				t.Errorf("hook ran = %v, want %v", hooked, c.hooked)
+			}
+		})
+	}
+}
@@ -2,25 +2,48 @@ package cli
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 
-	configfileeditor "github.com/nduyhai/gocraft/internal/adapters/outbound/config/fileeditor"
-	"github.com/nduyhai/gocraft/internal/adapters/outbound/context/contextimpl"
-	amfileeditor "github.com/nduyhai/gocraft/internal/adapters/outbound/di/fileeditor"
-	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/oswriter"
-	gomodfileeditor "github.com/nduyhai/gocraft/internal/adapters/outbound/gomod/fileeditor"
-	"github.com/nduyhai/gocraft/internal/adapters/outbound/rendering/texttmpl"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/archive"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/memfs"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/osfs"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/tracked"
+	hookexec "github.com/nduyhai/gocraft/internal/adapters/outbound/hooks/exec"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/modules/platform/monorepo"
+	sourcemodule "github.com/nduyhai/gocraft/internal/adapters/outbound/modules/source"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/registry/embed_registry"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/templates/embed_repo"
+	"github.com/nduyhai/gocraft/internal/core/entity"
 	"github.com/nduyhai/gocraft/internal/core/ports"
 	"github.com/nduyhai/gocraft/internal/core/usecase"
 	"github.com/spf13/cobra"
 )
 
+// Layouts supported by `new --layout`.
+const (
+	layoutSingle   = "single"
+	layoutMonorepo = "monorepo"
+)
+
 func newNewCmd(reg ports.Registry) *cobra.Command {
 	var (
 		module string
 		with   []string
 		set    []string
+		from   string
+		preset string
+		dryRun bool
+
+		archivePath   string
+		archiveFormat string
+		hooks         hookFlags
+		gen           genFlags
+		workspace     bool
+		sharedPkg     bool
+		layout        string
+		trust         bool
 	)
 
 	cmd := &cobra.Command{
@@ -28,56 +51,220 @@ func newNewCmd(reg ports.Registry) *cobra.Command {
 		Short: "Generate a new project",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			name := args[0]
+			// The project may be a path (e.g. services/billing in a workspace); its base is the name
+			target := filepath.Clean(args[0])
+			name := filepath.Base(target)
+			cfg, err := loadSettings()
+			if err != nil {
+				return err
+			}
+			if module == "" {
+				module = cfg.Module(name)
+			}
 			if module == "" {
 				module = fmt.Sprintf("github.com/you/%s", name)
 			}
-			target := filepath.Join(".", name)
-
-			// Prepare outbound collaborators
-			renderer := texttmpl.New()
-			writer := oswriter.New()
+			if sharedPkg && !workspace {
+				return fmt.Errorf("--shared-pkg requires --workspace")
+			}
+			if workspace && archivePath != "" {
+				return fmt.Errorf("--workspace cannot be combined with --archive")
+			}
+			if archivePath == "" {
+				hooks.applySettings(cmd, cfg.Hooks)
+			}
+			var p entity.Preset
+			if preset != "" {
+				repo, err := newPresetRepo(cfg)
+				if err != nil {
+					return err
+				}
+				if p, err = (usecase.LoadPreset{Repo: repo, Registry: reg}).Execute(preset); err != nil {
+					return err
+				}
+			}
+			switch layout {
+			case layoutSingle:
+				with = uniqueModules(p.Modules, cfg.Modules, with)
+			case layoutMonorepo:
+				if len(with) > 0 || from != "" || workspace || preset != "" {
+					return fmt.Errorf("--layout monorepo creates the repository only; add services with `gocraft service add <name> --with ...`")
+				}
+				hooks.modDir = monorepo.PlatformDir
+			default:
+				return fmt.Errorf("unknown layout %q (use %s or %s)", layout, layoutSingle, layoutMonorepo)
+			}
+			if archivePath != "" && hooks.any() && !hooks.noHooks {
+				return fmt.Errorf("--git, --tidy and --verify cannot be combined with --archive")
+			}
 
-			// Build module context
-			// Build GoMod editor bound to target directory
-			gomod := gomodfileeditor.New(target)
-			adaptersEditor := amfileeditor.New(target)
-			cfgEditor := configfileeditor.New(target)
 			vals := map[string]any{"Name": name, "Module": module}
 			if len(set) > 0 {
 				mergeSetsInto(vals, set)
 			}
-			ctx := contextimpl.New(
-				target,
-				writer,
-				renderer,
-				gomod,
-				adaptersEditor,
-				cfgEditor,
-				vals,
-			)
+			// --set wins over the preset, which wins over settings
+			mergeMissingInto(vals, copyValues(p.Values))
+			applySettingsValues(vals, cfg)
+
+			// Optional external template set, applied as an ad-hoc module after platform:base
+			// through a registry of this invocation only
+			runReg := reg
+			mods := uniqueModules([]string{"platform:base"}, with)
+			if layout == layoutMonorepo {
+				mods = []string{"platform:monorepo"}
+			}
+			if from != "" {
+				dir, cleanup, err := sourcemodule.Resolve(from)
+				if err != nil {
+					return err
+				}
+				defer cleanup()
+				src, err := sourcemodule.Load(dir)
+				if err != nil {
+					return err
+				}
+				// Commands from a repository run on this machine: show them and ask first
+				if sourcemodule.IsRemote(from) && len(src.Commands()) > 0 && archivePath == "" && !dryRun && !hooks.noHooks && !hooks.skipHooks && !trust {
+					if err := confirmSourceHooks(cmd, from, src.Commands()); err != nil {
+						return err
+					}
+				}
+				runReg = withModules(reg, src)
+				mergeMissingInto(vals, src.Values())
+				mods = append(mods, src.Name())
+			}
+			// wire_gen.go is regenerated by running wire on the written project, which an
+			// archive never is
+			if di, _ := ports.DIMode(vals); di == ports.DIWire && archivePath != "" {
+				return fmt.Errorf("--set di=wire cannot be combined with --archive: wire_gen.go is generated by running wire in the written project")
+			}
+			// Stage the whole generation in memory so a failing module leaves nothing behind;
+			// archives are built without touching the disk at all
+			disk := osfs.New()
+			stage := memfs.NewOverlay(disk)
+			if archivePath != "" {
+				stage = memfs.New()
+			}
+			opts, err := gen.options(cmd, cfg)
+			if err != nil {
+				return err
+			}
+			// Record which module wrote each file so verification failures can be attributed
+			files := tracked.New(stage)
+			ctx := newModuleCtx(files, target, vals, embed_repo.New(runReg, templateDirs(".", cfg)), opts).WithTracker(files)
+			origins := func(file string) []string { return files.Origins(filepath.Join(target, hooks.modDir, file)) }
 
 			// Use usecase to apply module(s) with injected registry
-			uc := usecase.ApplyModules{Registry: reg}
-			mods := append([]string{"platform:base"}, with...)
+			uc := usecase.ApplyModules{Registry: runReg}
 			if err := uc.Execute(ctx, mods...); err != nil {
 				return err
 			}
-			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Project generated at %s\n", target)
+			var workPath string
+			if workspace {
+				ctx.Enter("workspace")
+				if workPath, err = joinWorkspace(files, target, module, ctx.GoMod(), sharedPkg); err != nil {
+					return err
+				}
+			}
+			printVersionReport(cmd.ErrOrStderr(), ctx.GoMod())
+			hook := hooks.pipeline(ctx.Hooks(), origins, hookexec.GitInit("chore: initial commit"))
+			if dryRun {
+				printChanges(cmd.OutOrStdout(), stage.Changes())
+				printPlannedSteps(cmd.OutOrStdout(), hook)
+				return nil
+			}
+			out := cmd.OutOrStdout()
+			if archivePath != "" {
+				if err := writeArchive(cmd, stage, archivePath, archiveFormat); err != nil {
+					return fmt.Errorf("write archive: %w", err)
+				}
+				dest := archivePath
+				if archivePath == "-" {
+					out, dest = cmd.ErrOrStderr(), "stdout"
+				}
+				_, _ = fmt.Fprintf(out, "Project archived to %s\n", dest)
+			} else {
+				if err := stage.Commit(disk); err != nil {
+					return fmt.Errorf("write project: %w", err)
+				}
+				_, _ = fmt.Fprintf(out, "Project generated at %s\n", target)
+				if workPath != "" {
+					_, _ = fmt.Fprintf(out, "Added to workspace %s\n", workPath)
+				}
+			}
 			if len(with) > 0 {
-				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Applied modules: %s\n", strings.Join(with, ", "))
+				_, _ = fmt.Fprintf(out, "Applied modules: %s\n", strings.Join(with, ", "))
+			}
+			if from != "" {
+				_, _ = fmt.Fprintf(out, "Applied template source: %s\n", from)
+			}
+			if archivePath == "" {
+				return runHooks(cmd, out, hook, target)
 			}
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVarP(&module, "module", "m", "", "Go module path (default: github.com/you/<name>)")
+	cmd.Flags().StringVarP(&module, "module", "m", "", "Go module path (default: <module_prefix>/<name>, github.com/you/<name> without one)")
 	cmd.Flags().StringSliceVar(&with, "with", nil, "Additional modules to apply (e.g. --with http:gin)")
 	cmd.Flags().StringSliceVar(&set, "set", nil, "Set template values (key=value). Supports dot paths, e.g., --set gorm.driver=postgres")
+	cmd.Flags().StringVar(&preset, "preset", "", "Start from a named preset of modules and values (see `gocraft presets list`); --with and --set add to it")
+	cmd.Flags().StringVar(&from, "from", "", "Apply a template set from a directory or git repository (e.g. ./company-template, git+file:///srv/templates.git#v2)")
+	cmd.Flags().BoolVar(&trust, "trust", false, "Run the post-generate commands of a git --from template source without asking")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the files that would be written without touching the disk")
+	cmd.Flags().StringVar(&archivePath, "archive", "", "Write the project as an archive (.zip, .tar.gz) instead of a directory; '-' writes to stdout")
+	cmd.Flags().StringVar(&archiveFormat, "archive-format", "", "Archive format (zip|tar.gz); inferred from --archive, zip for stdout")
+	hooks.register(cmd, "Initialize a git repository and commit the generated project")
+	gen.register(cmd)
+	cmd.Flags().StringVar(&layout, "layout", layoutSingle, "Project layout: single (one module) or monorepo (go.work, services/ and a shared platform library)")
+	cmd.Flags().BoolVar(&workspace, "workspace", false, "Add the project to the enclosing go.work with a use directive")
+	cmd.Flags().BoolVar(&sharedPkg, "shared-pkg", false, "With --workspace, require the workspace's shared pkg/ module (created if missing) via a replace")
+	cmd.Flags().BoolVar(&hooks.verify, "verify", false, "Build and vet the generated project (implies --tidy) and report failures per module")
 	// Template (-t) and output (-o) flags are no longer needed; default template is platform:base via modules and output is ./<name>
 	return cmd
 }
 
+// withModules returns a registry holding reg's modules plus mods, leaving reg itself alone.
+func withModules(reg ports.Registry, mods ...ports.Module) ports.Registry {
+	r := embed_registry.New()
+	for _, m := range append(reg.List(), mods...) {
+		r.Register(m)
+	}
+	return r
+}
+
+// writeArchive writes the staged project as an archive to path, or to stdout when path is "-".
+func writeArchive(cmd *cobra.Command, stage *memfs.FS, path, format string) error {
+	var (
+		f   archive.Format
+		err error
+	)
+	switch {
+	case format != "":
+		f, err = archive.ParseFormat(format)
+	case path == "-":
+		f = archive.Zip
+	default:
+		f, err = archive.FormatFromPath(path)
+	}
+	if err != nil {
+		return err
+	}
+	if path == "-" {
+		return archive.Write(cmd.OutOrStdout(), f, stage, ".")
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := archive.Write(file, f, stage, "."); err != nil {
+		_ = file.Close()
+		_ = os.Remove(path)
+		return err
+	}
+	return file.Close()
+}
+
 // mergeSetsInto parses key=value pairs and merges them into vals map with dot-path nesting
 func mergeSetsInto(vals map[string]any, sets []string) {
 	for _, kv := range sets {
@@ -97,6 +284,22 @@ func mergeSetsInto(vals map[string]any, sets []string) {
 	}
 }
 
+// mergeMissingInto deep-merges src into dst, keeping values already present in dst.
+func mergeMissingInto(dst, src map[string]any) {
+	for k, v := range src {
+		existing, ok := dst[k]
+		if !ok {
+			dst[k] = v
+			continue
+		}
+		em, ok1 := existing.(map[string]any)
+		sm, ok2 := v.(map[string]any)
+		if ok1 && ok2 {
+			mergeMissingInto(em, sm)
+		}
+	}
+}
+
 func setNested(m map[string]any, path []string, value any) {
 	cur := m
 	for i, p := range path {
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/presets"
+	"github.com/nduyhai/gocraft/internal/core/entity"
+	"github.com/nduyhai/gocraft/internal/core/ports"
+	"github.com/nduyhai/gocraft/internal/core/usecase"
+	"github.com/nduyhai/gocraft/internal/platform/settings"
+	"github.com/spf13/cobra"
+)
+
+// newPresetsCmd creates the `presets` command group.
+func newPresetsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "presets",
+		Short: "Inspect presets (named bundles of modules and values for `new --preset`)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(newPresetsListCmd())
+	return cmd
+}
+
+// newPresetsListCmd creates the `presets list` command which lists built-in presets and those
+// defined in user and project settings.
+func newPresetsListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List available presets",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadSettings()
+			if err != nil {
+				return err
+			}
+			repo, err := newPresetRepo(cfg)
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "NAME\tSOURCE\tMODULES\tVALUES\tSUMMARY")
+			for _, p := range (usecase.ListPresets{Repo: repo}).Execute() {
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p.Name, p.Source, strings.Join(p.Modules, ","), formatValues(p.Values), p.Summary)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// newPresetRepo returns the built-in presets overlaid with those from settings.
+func newPresetRepo(s settings.Settings) (ports.PresetRepo, error) {
+	extra := make([]entity.Preset, 0, len(s.Presets))
+	for _, p := range s.Presets {
+		extra = append(extra, p)
+	}
+	return presets.New(extra...)
+}
+
+// formatValues renders nested values as sorted dotted key=value pairs.
+func formatValues(vals map[string]any) string {
+	var pairs []string
+	var walk func(prefix string, m map[string]any)
+	walk = func(prefix string, m map[string]any) {
+		for k, v := range m {
+			if sub, ok := v.(map[string]any); ok {
+				walk(prefix+k+".", sub)
+				continue
+			}
+			pairs = append(pairs, fmt.Sprintf("%s%s=%v", prefix, k, v))
+		}
+	}
+	walk("", vals)
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -23,6 +23,11 @@ func NewRootCmd(reg ports.Registry) *cobra.Command {
 	cmd.AddCommand(newNewCmd(reg))
 	cmd.AddCommand(newListCmd(reg))
 	cmd.AddCommand(newAddCmd(reg))
+	cmd.AddCommand(newTemplatesCmd(reg))
+	cmd.AddCommand(newServeCmd(reg))
+	cmd.AddCommand(newServiceCmd(reg))
+	cmd.AddCommand(newConfigCmd())
+	cmd.AddCommand(newPresetsCmd())
 	cmd.AddCommand(newCompletionCmd())
 	cmd.AddCommand(newVersionCmd())
 
@@ -31,7 +36,7 @@ func NewRootCmd(reg ports.Registry) *cobra.Command {
 
 	cmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose logging")
 
-	cmd.SetErrPrefix("error: ")
+	cmd.SetErrPrefix("error:")
 	cmd.SetOut(os.Stdout)
 	cmd.SetErr(os.Stderr)
 
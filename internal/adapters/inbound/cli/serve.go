@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nduyhai/gocraft/internal/adapters/inbound/httpapi"
+	"github.com/nduyhai/gocraft/internal/core/ports"
+	"github.com/spf13/cobra"
+)
+
+// newServeCmd creates the `serve` command which exposes the module registry over a local HTTP API.
+func newServeCmd(reg ports.Registry) *cobra.Command {
+	var (
+		addr string
+		gen  genFlags
+	)
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the module registry and project generation over HTTP",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadSettings()
+			if err != nil {
+				return err
+			}
+			opts, err := gen.options(cmd, cfg)
+			if err != nil {
+				return err
+			}
+			repo := newTemplateRepo(reg)
+			api := httpapi.New(reg, func(fsys ports.FileSystem, root string, vals map[string]any) ports.Ctx {
+				return newModuleCtx(fsys, root, vals, repo, opts)
+			}).WithModulePrefix(cfg.ModulePrefix).
+				WithDefaults(cfg.Modules, func(vals map[string]any) { applySettingsValues(vals, cfg) })
+
+			ln, err := net.Listen("tcp", addr)
+			if err != nil {
+				return fmt.Errorf("listen: %w", err)
+			}
+			srv := &http.Server{Handler: api, ReadHeaderTimeout: 10 * time.Second}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Serving on http://%s\n", ln.Addr())
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			errc := make(chan error, 1)
+			go func() { errc <- srv.Serve(ln) }()
+			select {
+			case err := <-errc:
+				return err
+			case <-ctx.Done():
+			}
+			shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := srv.Shutdown(shutdown); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("shutdown: %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&addr, "addr", "127.0.0.1:7070", "Address to listen on")
+	gen.register(cmd)
+	return cmd
+}
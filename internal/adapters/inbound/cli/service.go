@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/memfs"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/osfs"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/tracked"
+	goworkfileeditor "github.com/nduyhai/gocraft/internal/adapters/outbound/gowork/fileeditor"
+	hookexec "github.com/nduyhai/gocraft/internal/adapters/outbound/hooks/exec"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/modules/platform/monorepo"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/templates/embed_repo"
+	"github.com/nduyhai/gocraft/internal/core/ports"
+	"github.com/nduyhai/gocraft/internal/core/usecase"
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/modfile"
+)
+
+// newServiceCmd creates the `service` command group for repositories created with --layout monorepo.
+func newServiceCmd(reg ports.Registry) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "service",
+		Short: "Manage services in a monorepo",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(newServiceAddCmd(reg))
+	return cmd
+}
+
+// newServiceAddCmd creates the `service add` command which generates services/<name> using the
+// repository's shared platform library and adds it to go.work.
+func newServiceAddCmd(reg ports.Registry) *cobra.Command {
+	var (
+		module string
+		with   []string
+		set    []string
+		dryRun bool
+		hooks  hookFlags
+		gen    genFlags
+	)
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add a service to the monorepo",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			if name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+				return fmt.Errorf("invalid service name %q", name)
+			}
+			cfg, err := loadSettings()
+			if err != nil {
+				return err
+			}
+			hooks.applySettings(cmd, cfg.Hooks)
+			with = uniqueModules(cfg.Modules, with)
+			disk := osfs.New()
+			workPath, err := goworkfileeditor.Find(disk, ".")
+			if err != nil {
+				return fmt.Errorf("not in a monorepo: %w", err)
+			}
+			repo := filepath.Dir(workPath)
+			platformMod := filepath.Join(repo, monorepo.PlatformDir, "go.mod")
+			data, err := disk.ReadFile(platformMod)
+			if err != nil {
+				return fmt.Errorf("not in a monorepo: %s not found (create one with `gocraft new <repo> --layout monorepo`)", platformMod)
+			}
+			platform := modfile.ModulePath(data)
+			if platform == "" {
+				return fmt.Errorf("%s has no module directive", platformMod)
+			}
+			target := filepath.Join(repo, monorepo.ServicesDir, name)
+			if _, err := disk.Stat(filepath.Join(target, "go.mod")); err == nil {
+				return fmt.Errorf("service %s already exists at %s", name, target)
+			}
+			if module == "" {
+				module = strings.TrimSuffix(platform, "/"+monorepo.PlatformDir) + "/" + monorepo.ServicesDir + "/" + name
+			}
+
+			vals := map[string]any{"Name": name, "Module": module, "Platform": platform}
+			if len(set) > 0 {
+				mergeSetsInto(vals, set)
+			}
+			applySettingsValues(vals, cfg)
+			opts, err := gen.options(cmd, cfg)
+			if err != nil {
+				return err
+			}
+			stage := memfs.NewOverlay(disk)
+			files := tracked.New(stage)
+			ctx := newModuleCtx(files, target, vals, embed_repo.New(reg, templateDirs(repo, cfg)), opts).WithTracker(files)
+
+			// The service skeleton goes first so modules requiring platform:base find a go.mod and skip it
+			uc := usecase.ApplyModules{Registry: reg}
+			if err := uc.Execute(ctx, "platform:service"); err != nil {
+				return err
+			}
+			if err := uc.Execute(ctx, with...); err != nil {
+				return err
+			}
+			ctx.Enter("workspace")
+			if _, err := joinWorkspace(files, target, module, ctx.GoMod(), false); err != nil {
+				return err
+			}
+			printVersionReport(cmd.ErrOrStderr(), ctx.GoMod())
+			origins := func(file string) []string { return files.Origins(filepath.Join(target, file)) }
+			hook := hooks.pipeline(ctx.Hooks(), origins, hookexec.GitCommit("chore: add service "+name, touchedPaths(target, stage.Changes())...))
+
+			out := cmd.OutOrStdout()
+			if dryRun {
+				printChanges(out, stage.Changes())
+				printPlannedSteps(out, hook)
+				return nil
+			}
+			if err := stage.Commit(disk); err != nil {
+				return fmt.Errorf("write service: %w", err)
+			}
+			_, _ = fmt.Fprintf(out, "Service generated at %s\n", target)
+			if len(with) > 0 {
+				_, _ = fmt.Fprintf(out, "Applied modules: %s\n", strings.Join(with, ", "))
+			}
+			return runHooks(cmd, out, hook, target)
+		},
+	}
+	cmd.Flags().StringVarP(&module, "module", "m", "", "Go module path (default: <repo module>/services/<name>)")
+	cmd.Flags().StringSliceVar(&with, "with", nil, "Additional modules to apply (e.g. --with http:chi)")
+	cmd.Flags().StringSliceVar(&set, "set", nil, "Set template values (key=value). Supports dot paths, e.g., --set gorm.driver=postgres")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the files that would be written without touching the disk")
+	gen.register(cmd)
+	hooks.register(cmd, "Commit the new service to the repository")
+	cmd.Flags().BoolVar(&hooks.verify, "verify", false, "Build and vet the service (implies --tidy) and report failures per module")
+	return cmd
+}
@@ -0,0 +1,69 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nduyhai/gocraft/internal/adapters/inbound/cli"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/modules/register"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/registry/embed_registry"
+)
+
+// gocraft runs the CLI with args in the current directory, isolated from user settings.
+func gocraft(t *testing.T, args ...string) {
+	t.Helper()
+	if out, err := gocraftIn("", args...); err != nil {
+		t.Fatalf("gocraft %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+// gocraftIn runs the CLI with args and stdin, returning its combined output.
+func gocraftIn(stdin string, args ...string) (string, error) {
+	reg := embed_registry.New()
+	register.Builtins(reg)
+	cmd := cli.NewRootCmd(reg)
+	var out bytes.Buffer
+	cmd.SetIn(strings.NewReader(stdin))
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestServiceAdd_Monorepo(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	gocraft(t, "new", "shop", "--layout", "monorepo", "-m", "example.com/shop")
+	t.Chdir("shop")
+	gocraft(t, "service", "add", "billing", "--with", "http:chi")
+
+	work, err := os.ReadFile("go.work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(work), "./services/billing") || !strings.Contains(string(work), "./platform") {
+		t.Errorf("go.work does not use the platform and the service:\n%s", work)
+	}
+	svc := filepath.Join("services", "billing")
+	gomod, err := os.ReadFile(filepath.Join(svc, "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(gomod), "module example.com/shop/services/billing") || !strings.Contains(string(gomod), "github.com/go-chi/chi") {
+		t.Errorf("service go.mod:\n%s", gomod)
+	}
+	if _, err := os.Stat(filepath.Join(svc, "internal", "adapters", "inbound", "http", "chi", "module.go")); err != nil {
+		t.Errorf("http:chi not applied to the service: %v", err)
+	}
+	// The service skeleton replaces platform:base, whose files belong to the platform module
+	for _, f := range []string{"internal/platform/logger/module.go", "internal/platform/app/app.go", "internal/core/ports/.gitkeep"} {
+		if _, err := os.Stat(filepath.Join(svc, filepath.FromSlash(f))); err == nil {
+			t.Errorf("platform:base file %s written into the service", f)
+		}
+	}
+}
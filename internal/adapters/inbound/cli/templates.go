@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/templates/embed_repo"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/templates/overlay"
+	"github.com/nduyhai/gocraft/internal/core/ports"
+	"github.com/nduyhai/gocraft/internal/core/usecase"
+	"github.com/nduyhai/gocraft/internal/platform/paths"
+	"github.com/spf13/cobra"
+)
+
+// newTemplatesCmd creates the `templates` command group for inspecting and customizing module templates.
+func newTemplatesCmd(reg ports.Registry) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "templates",
+		Short: "Inspect and customize module templates",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(newTemplatesListCmd(reg))
+	cmd.AddCommand(newTemplatesShowCmd(reg))
+	cmd.AddCommand(newTemplatesEjectCmd(reg))
+	return cmd
+}
+
+// newTemplatesListCmd creates the `templates list` command which lists template files per module,
+// showing their kind and whether each is built-in or served from an override directory.
+func newTemplatesListCmd(reg ports.Registry) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list [module]",
+		Short: "List module templates and where they are loaded from",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo := newTemplateRepo(reg)
+			var tpls []ports.Template
+			if len(args) == 1 {
+				tpl, err := usecase.LoadTemplate{Repo: repo}.Execute(args[0])
+				if err != nil {
+					return err
+				}
+				tpls = append(tpls, tpl)
+			} else {
+				all, err := usecase.ListTemplates{Repo: repo}.Execute()
+				if err != nil {
+					return err
+				}
+				tpls = all
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "MODULE\tFILE\tKIND\tSOURCE")
+			for _, tpl := range tpls {
+				for _, f := range tpl.Files {
+					_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", tpl.Name, f.Path, kindLabel(f.Kind), f.Source)
+				}
+			}
+			return w.Flush()
+		},
+	}
+	return cmd
+}
+
+// newTemplatesShowCmd creates the `templates show` command which prints a module's template
+// contents, either a single file or all of them.
+func newTemplatesShowCmd(reg ports.Registry) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show <module> [file]",
+		Short: "Print a module's templates (all files, or a single file)",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tpl, err := usecase.LoadTemplate{Repo: newTemplateRepo(reg)}.Execute(args[0])
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			if len(args) == 2 {
+				for _, f := range tpl.Files {
+					if f.Path == args[1] {
+						_, _ = fmt.Fprint(out, f.Content)
+						return nil
+					}
+				}
+				return fmt.Errorf("template %s has no file %s", args[0], args[1])
+			}
+			for _, f := range tpl.Files {
+				_, _ = fmt.Fprintf(out, "==> %s (%s, %s) <==\n%s\n", f.Path, kindLabel(f.Kind), f.Source, f.Content)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+// kindLabel names a template kind for listings: config defaults and schemas are merged into
+// the project config rather than rendered as files.
+func kindLabel(kind string) string {
+	if kind == ports.KindFile {
+		return "file"
+	}
+	return kind
+}
+
+// newTemplateRepo builds the template repository for the current directory's override search path.
+func newTemplateRepo(reg ports.Registry) ports.TemplateRepo {
+	cfg, _ := loadSettings() // unreadable settings only lose the configured override dir here
+	return embed_repo.New(reg, templateDirs(".", cfg))
+}
+
+// newTemplatesEjectCmd creates the `templates eject` command which copies a module's built-in
+// templates into an override directory so they can be customized.
+func newTemplatesEjectCmd(reg ports.Registry) *cobra.Command {
+	var (
+		user  bool
+		force bool
+	)
+	cmd := &cobra.Command{
+		Use:   "eject <module>",
+		Short: "Copy a module's built-in templates into .gocraft/templates/<module> for customization",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			m, ok := reg.Get(name)
+			if !ok {
+				return fmt.Errorf("unknown module: %s", name)
+			}
+			src, ok := m.(ports.TemplateSource)
+			if !ok {
+				return fmt.Errorf("module %s has no templates", name)
+			}
+
+			root := filepath.Join(paths.ProjectDir, "templates")
+			if user {
+				dir, err := paths.UserConfigDir()
+				if err != nil {
+					return fmt.Errorf("user config dir: %w", err)
+				}
+				root = filepath.Join(dir, "templates")
+			}
+			dest := filepath.Join(root, overlay.ModuleDir(name))
+
+			var written, skipped int
+			err := fs.WalkDir(src.Templates(), ".", func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() {
+					return nil
+				}
+				target := filepath.Join(dest, filepath.FromSlash(path))
+				if !force {
+					if _, err := os.Stat(target); err == nil {
+						skipped++
+						_, _ = fmt.Fprintf(cmd.OutOrStdout(), "skip %s (exists)\n", target)
+						return nil
+					} else if !errors.Is(err, os.ErrNotExist) {
+						return err
+					}
+				}
+				b, err := fs.ReadFile(src.Templates(), path)
+				if err != nil {
+					return err
+				}
+				if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+					return err
+				}
+				if err := os.WriteFile(target, b, 0o644); err != nil {
+					return err
+				}
+				written++
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "write %s\n", target)
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("eject %s: %w", name, err)
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Ejected %d file(s) to %s (%d skipped)\n", written, dest, skipped)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&user, "user", false, "Eject into the user config dir (~/.config/gocraft/templates) instead of the project")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite existing override files")
+	return cmd
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	goworkfileeditor "github.com/nduyhai/gocraft/internal/adapters/outbound/gowork/fileeditor"
+	"github.com/nduyhai/gocraft/internal/core/ports"
+	"golang.org/x/mod/modfile"
+)
+
+// sharedPkgDir is the workspace-relative directory of the optional shared module.
+const sharedPkgDir = "pkg"
+
+// joinWorkspace adds the module at dir to the go.work enclosing it. With sharedPkg, the
+// workspace's shared pkg module (created on first use) is added too and required by the
+// new module through a replace directive. It returns the go.work path.
+func joinWorkspace(fsys ports.FileSystem, dir, modulePath string, gm ports.GoModEditor, sharedPkg bool) (string, error) {
+	workPath, err := goworkfileeditor.Find(fsys, dir)
+	if err != nil {
+		return "", fmt.Errorf("%w (create one with `go work init`)", err)
+	}
+	work := goworkfileeditor.NewFS(fsys, workPath)
+	if err := work.Use(dir); err != nil {
+		return "", fmt.Errorf("update %s: %w", workPath, err)
+	}
+	if !sharedPkg {
+		return workPath, nil
+	}
+
+	pkgDir := filepath.Join(work.Dir(), sharedPkgDir)
+	pkgPath, err := ensureSharedPkg(fsys, work, pkgDir, dir, modulePath)
+	if err != nil {
+		return "", err
+	}
+	if err := work.Use(pkgDir); err != nil {
+		return "", fmt.Errorf("update %s: %w", workPath, err)
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(absDir, pkgDir)
+	if err != nil {
+		return "", err
+	}
+	rel = filepath.ToSlash(rel)
+	if !strings.HasPrefix(rel, ".") {
+		rel = "./" + rel
+	}
+	if err := gm.Add(pkgPath, "v0.0.0"); err != nil {
+		return "", fmt.Errorf("go.mod require %s: %w", pkgPath, err)
+	}
+	if err := gm.Replace(pkgPath, rel); err != nil {
+		return "", fmt.Errorf("go.mod replace %s: %w", pkgPath, err)
+	}
+	return workPath, nil
+}
+
+// ensureSharedPkg returns the module path of the shared module in pkgDir, creating a minimal
+// module there when it does not exist yet.
+func ensureSharedPkg(fsys ports.FileSystem, work *goworkfileeditor.Editor, pkgDir, dir, modulePath string) (string, error) {
+	gomod := filepath.Join(pkgDir, "go.mod")
+	if data, err := fsys.ReadFile(gomod); err == nil {
+		if p := modfile.ModulePath(data); p != "" {
+			return p, nil
+		}
+		return "", fmt.Errorf("%s has no module directive", gomod)
+	}
+
+	// Derive the path from the new module: github.com/acme/mono/services/billing living in
+	// services/billing gives github.com/acme/mono/pkg; otherwise use a sibling of the module.
+	pkgPath := path.Join(path.Dir(modulePath), sharedPkgDir)
+	if rel, err := work.Rel(dir); err == nil {
+		if prefix, ok := strings.CutSuffix(modulePath, strings.TrimPrefix(rel, ".")); ok && prefix != "" {
+			pkgPath = prefix + "/" + sharedPkgDir
+		}
+	}
+	goVersion, _ := work.GoVersion()
+	if goVersion == "" {
+		if data, err := fsys.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+			if mf, err := modfile.ParseLax("go.mod", data, nil); err == nil && mf.Go != nil {
+				goVersion = mf.Go.Version
+			}
+		}
+	}
+
+	if err := fsys.MkdirAll(pkgDir, 0o755); err != nil {
+		return "", err
+	}
+	content := "module " + pkgPath + "\n"
+	if goVersion != "" {
+		content += "\ngo " + goVersion + "\n"
+	}
+	if err := fsys.WriteFile(gomod, []byte(content), 0o644); err != nil {
+		return "", err
+	}
+	doc := "// Package pkg holds code shared by the modules of this workspace.\npackage pkg\n"
+	if err := fsys.WriteFile(filepath.Join(pkgDir, "doc.go"), []byte(doc), 0o644); err != nil {
+		return "", err
+	}
+	return pkgPath, nil
+}
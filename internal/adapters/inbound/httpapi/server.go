@@ -0,0 +1,241 @@
+package httpapi
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/archive"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/memfs"
+	"github.com/nduyhai/gocraft/internal/core/ports"
+	"github.com/nduyhai/gocraft/internal/core/usecase"
+	"golang.org/x/mod/module"
+)
+
+//go:embed static/index.html
+var static embed.FS
+
+// CtxFactory builds a module context generating into root through fsys.
+type CtxFactory func(fsys ports.FileSystem, root string, vals map[string]any) ports.Ctx
+
+// Server exposes the module registry over HTTP:
+//
+//	GET  /          HTML form
+//	GET  /modules   module metadata and default options
+//	POST /plan      resolved module order and conflicts
+//	POST /projects  generated project as a zip archive
+//
+// Projects are generated entirely in memory; nothing is written to the server's disk.
+type Server struct {
+	reg            ports.Registry
+	newCtx         CtxFactory
+	mux            *http.ServeMux
+	modulePrefix   string
+	defaultModules []string
+	defaultValues  func(vals map[string]any)
+}
+
+// New returns a Server for reg; newCtx wires the outbound adapters for each generation.
+func New(reg ports.Registry, newCtx CtxFactory) *Server {
+	s := &Server{reg: reg, newCtx: newCtx, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /{$}", s.index)
+	s.mux.HandleFunc("GET /modules", s.modules)
+	s.mux.HandleFunc("POST /plan", s.plan)
+	s.mux.HandleFunc("POST /projects", s.projects)
+	return s
+}
+
+// WithModulePrefix sets the prefix of the default module path, <prefix>/<name>, for requests
+// without one. Without a prefix such requests are rejected.
+func (s *Server) WithModulePrefix(prefix string) *Server {
+	s.modulePrefix = strings.TrimSuffix(prefix, "/")
+	return s
+}
+
+// WithDefaults sets the modules applied before the requested ones and a function filling in
+// the values a request leaves out, as `gocraft new` does with the settings' modules and values.
+func (s *Server) WithDefaults(modules []string, values func(vals map[string]any)) *Server {
+	s.defaultModules, s.defaultValues = modules, values
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) { s.mux.ServeHTTP(w, r) }
+
+// Module is the JSON view of a registered module.
+type Module struct {
+	Name      string         `json:"name"`
+	Label     string         `json:"label"`
+	Version   string         `json:"version"`
+	Summary   string         `json:"summary"`
+	Tags      []string       `json:"tags"`
+	Requires  []string       `json:"requires"`
+	Conflicts []string       `json:"conflicts"`
+	Options   map[string]any `json:"options"` // default configuration, overridable through values
+}
+
+// ProjectRequest describes a project to plan or generate.
+type ProjectRequest struct {
+	Name    string         `json:"name"`
+	Module  string         `json:"module"`  // Go module path; default <module prefix>/<name>
+	Modules []string       `json:"modules"` // applied on top of platform:base
+	Values  map[string]any `json:"values"`
+}
+
+func (s *Server) index(w http.ResponseWriter, r *http.Request) {
+	b, _ := static.ReadFile("static/index.html")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(b)
+}
+
+func (s *Server) modules(w http.ResponseWriter, r *http.Request) {
+	mods := usecase.ListModules{Registry: s.reg}.Execute()
+	out := make([]Module, 0, len(mods))
+	for _, m := range mods {
+		out = append(out, Module{
+			Name:      m.Name(),
+			Label:     m.Label(),
+			Version:   m.Version(),
+			Summary:   m.Summary(),
+			Tags:      m.Tags(),
+			Requires:  m.Requires(),
+			Conflicts: m.Conflicts(),
+			Options:   m.Defaults(),
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) plan(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	plan, err := usecase.PlanModules{Registry: s.reg}.Execute(s.modulesFor(req)...)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, plan)
+}
+
+func (s *Server) projects(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Module == "" && s.modulePrefix != "" {
+		req.Module = s.modulePrefix + "/" + req.Name
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	mods := s.modulesFor(req)
+	plan, err := usecase.PlanModules{Registry: s.reg}.Execute(mods...)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if len(plan.Conflicts) > 0 {
+		c := plan.Conflicts[0]
+		writeError(w, http.StatusConflict, fmt.Errorf("conflict: %s conflicts with %s", c.Module, c.With))
+		return
+	}
+
+	vals := map[string]any{}
+	for k, v := range req.Values {
+		vals[k] = v
+	}
+	if s.defaultValues != nil {
+		s.defaultValues(vals)
+	}
+	vals["Name"], vals["Module"] = req.Name, req.Module
+	// Nothing runs on the served archive, so wire_gen.go could not be regenerated
+	if di, err := ports.DIMode(vals); err != nil || di == ports.DIWire {
+		if err == nil {
+			err = errors.New("di=wire is not supported here: wire_gen.go is generated by running wire in the written project; use `gocraft new --set di=wire`")
+		}
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	stage := memfs.New()
+	ctx := s.newCtx(stage, req.Name, vals)
+	if err := (usecase.ApplyModules{Registry: s.reg}).Execute(ctx, mods...); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	// Buffer the archive so a failure can still be reported as JSON
+	var buf bytes.Buffer
+	if err := archive.Write(&buf, archive.Zip, stage, "."); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("write archive: %w", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", req.Name+".zip"))
+	_, _ = w.Write(buf.Bytes())
+}
+
+// maxRequestBytes bounds request bodies; project requests are small JSON documents.
+const maxRequestBytes = 1 << 20
+
+func decodeRequest(r *http.Request) (ProjectRequest, error) {
+	var req ProjectRequest
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxRequestBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		return req, fmt.Errorf("decode request: %w", err)
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	req.Module = strings.TrimSpace(req.Module)
+	return req, nil
+}
+
+// modulesFor returns the modules to apply, always starting with platform:base like
+// `gocraft new`, then the default modules and the requested ones.
+func (s *Server) modulesFor(req ProjectRequest) []string {
+	mods := []string{"platform:base"}
+	seen := map[string]bool{"platform:base": true}
+	for _, list := range [][]string{s.defaultModules, req.Modules} {
+		for _, m := range list {
+			if m = strings.TrimSpace(m); m != "" && !seen[m] {
+				seen[m] = true
+				mods = append(mods, m)
+			}
+		}
+	}
+	return mods
+}
+
+func (req ProjectRequest) validate() error {
+	switch {
+	case req.Name == "":
+		return errors.New("name is required")
+	case req.Name == "." || req.Name == ".." || path.Base(req.Name) != req.Name || strings.ContainsAny(req.Name, `\:`):
+		return fmt.Errorf("invalid project name %q", req.Name)
+	case req.Module == "":
+		return errors.New("module is required (no module_prefix is configured)")
+	}
+	// The module path ends up in go.mod and every import of the generated code
+	if err := module.CheckPath(req.Module); err != nil {
+		return fmt.Errorf("invalid module path: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
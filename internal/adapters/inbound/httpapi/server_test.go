@@ -0,0 +1,193 @@
+package httpapi_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nduyhai/gocraft/internal/adapters/inbound/httpapi"
+	configfileeditor "github.com/nduyhai/gocraft/internal/adapters/outbound/config/fileeditor"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/context/contextimpl"
+	difileeditor "github.com/nduyhai/gocraft/internal/adapters/outbound/di/fileeditor"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/oswriter"
+	gomodfileeditor "github.com/nduyhai/gocraft/internal/adapters/outbound/gomod/fileeditor"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/modules/register"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/registry/embed_registry"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/rendering/texttmpl"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/templates/embed_repo"
+	"github.com/nduyhai/gocraft/internal/core/ports"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return serve(t, func(s *httpapi.Server) *httpapi.Server { return s })
+}
+
+func serve(t *testing.T, configure func(*httpapi.Server) *httpapi.Server) *httptest.Server {
+	t.Helper()
+	reg := embed_registry.New()
+	register.Builtins(reg)
+	repo := embed_repo.New(reg, nil)
+	srv := httptest.NewServer(configure(httpapi.New(reg, func(fsys ports.FileSystem, root string, vals map[string]any) ports.Ctx {
+		return contextimpl.New(root, oswriter.NewFS(fsys), texttmpl.New(),
+			gomodfileeditor.NewFS(fsys, root), difileeditor.NewFS(fsys, root), configfileeditor.NewFS(fsys, root), vals,
+		).WithFiles(fsys).WithTemplates(repo)
+	})))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestServer_Modules(t *testing.T) {
+	srv := newServer(t)
+	res, err := http.Get(srv.URL + "/modules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	var mods []httpapi.Module
+	if err := json.NewDecoder(res.Body).Decode(&mods); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	names := map[string]bool{}
+	for _, m := range mods {
+		names[m.Name] = true
+	}
+	if !names["platform:base"] || !names["http:chi"] {
+		t.Fatalf("modules = %v", names)
+	}
+}
+
+func TestServer_Plan(t *testing.T) {
+	srv := newServer(t)
+	res, err := http.Post(srv.URL+"/plan", "application/json", strings.NewReader(`{"modules":["http:gin","http:chi"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	var plan ports.Plan
+	if err := json.NewDecoder(res.Body).Decode(&plan); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if res.StatusCode != http.StatusOK || len(plan.Order) == 0 || plan.Order[0] != "platform:base" {
+		t.Fatalf("status %d, plan %+v", res.StatusCode, plan)
+	}
+	if len(plan.Conflicts) == 0 {
+		t.Fatalf("expected gin/chi conflict, got %+v", plan)
+	}
+}
+
+func TestServer_Projects(t *testing.T) {
+	srv := newServer(t)
+	body := `{"name":"myapp","module":"example.com/myapp","modules":["http:chi"],"values":{"app":{"env":"dev"}}}`
+	res, err := http.Post(srv.URL+"/projects", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	b, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status %d: %s", res.StatusCode, b)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatalf("zip: %v", err)
+	}
+	var gomod string
+	for _, f := range zr.File {
+		if f.Name == "myapp/go.mod" {
+			rc, _ := f.Open()
+			data, _ := io.ReadAll(rc)
+			_ = rc.Close()
+			gomod = string(data)
+		}
+	}
+	if !strings.Contains(gomod, "module example.com/myapp") || !strings.Contains(gomod, "github.com/go-chi/chi") {
+		t.Fatalf("go.mod = %q", gomod)
+	}
+}
+
+func TestServer_ProjectsRejectsBadName(t *testing.T) {
+	srv := newServer(t)
+	res, err := http.Post(srv.URL+"/projects", "application/json", strings.NewReader(`{"name":"../evil"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", res.StatusCode)
+	}
+}
+
+func TestServer_ProjectsRejectsWire(t *testing.T) {
+	srv := newServer(t)
+	body := `{"name":"myapp","module":"example.com/myapp","modules":["http:chi"],"values":{"di":"wire"}}`
+	res, err := http.Post(srv.URL+"/projects", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", res.StatusCode)
+	}
+}
+
+func TestServer_ProjectsModulePath(t *testing.T) {
+	srv := newServer(t)
+	for _, body := range []string{
+		`{"name":"myapp"}`,
+		`{"name":"myapp","module":"example.com/my app\n\nreplace x => ../x"}`,
+	} {
+		res, err := http.Post(srv.URL+"/projects", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = res.Body.Close()
+		if res.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, res.StatusCode)
+		}
+	}
+}
+
+func TestServer_Defaults(t *testing.T) {
+	srv := serve(t, func(s *httpapi.Server) *httpapi.Server {
+		return s.WithDefaults([]string{"http:chi"}, func(vals map[string]any) {
+			if _, ok := vals["di"]; !ok {
+				vals["di"] = "wire"
+			}
+		})
+	})
+
+	res, err := http.Post(srv.URL+"/plan", "application/json", strings.NewReader(`{"modules":["feature:makefile"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var plan ports.Plan
+	err = json.NewDecoder(res.Body).Decode(&plan)
+	_ = res.Body.Close()
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !strings.Contains(strings.Join(plan.Order, ","), "http:chi") {
+		t.Errorf("plan %v lacks the default module http:chi", plan.Order)
+	}
+
+	// The default di=wire is rejected like a requested one, unless the request overrides it
+	for body, want := range map[string]int{
+		`{"name":"myapp","module":"example.com/myapp"}`:                      http.StatusBadRequest,
+		`{"name":"myapp","module":"example.com/myapp","values":{"di":"fx"}}`: http.StatusOK,
+	} {
+		res, err := http.Post(srv.URL+"/projects", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = res.Body.Close()
+		if res.StatusCode != want {
+			t.Errorf("%s: status = %d, want %d", body, res.StatusCode, want)
+		}
+	}
+}
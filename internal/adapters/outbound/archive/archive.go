@@ -0,0 +1,143 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Format is an archive container format.
+type Format string
+
+const (
+	Zip   Format = "zip"
+	TarGz Format = "tar.gz"
+)
+
+// Source is a set of generated files to archive; *memfs.FS satisfies it.
+type Source interface {
+	Files() []string
+	ReadFile(name string) ([]byte, error)
+	Stat(name string) (fs.FileInfo, error)
+}
+
+// FormatFromPath infers the archive format from a file name (.zip, .tar.gz, .tgz).
+func FormatFromPath(path string) (Format, error) {
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return Zip, nil
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return TarGz, nil
+	}
+	return "", fmt.Errorf("cannot infer archive format from %q (use .zip, .tar.gz or .tgz)", path)
+}
+
+// ParseFormat validates a user-supplied format name.
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case Zip:
+		return Zip, nil
+	case TarGz, "tgz":
+		return TarGz, nil
+	}
+	return "", fmt.Errorf("unknown archive format %q (use zip or tar.gz)", s)
+}
+
+// Write archives every file of src into w. Entry names are the file paths made
+// relative to base and slash-separated, so base "." keeps paths as-is.
+func Write(w io.Writer, format Format, src Source, base string) error {
+	switch format {
+	case Zip:
+		return writeZip(w, src, base)
+	case TarGz:
+		return writeTarGz(w, src, base)
+	}
+	return fmt.Errorf("unknown archive format %q", format)
+}
+
+// entry is a single file ready to be archived.
+type entry struct {
+	name    string
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func entries(src Source, base string) ([]entry, error) {
+	var out []entry
+	for _, p := range src.Files() {
+		rel, err := filepath.Rel(base, p)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("file %s is outside %s", p, base)
+		}
+		data, err := src.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		fi, err := src.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		mod := fi.ModTime()
+		if mod.IsZero() {
+			mod = time.Now()
+		}
+		out = append(out, entry{name: filepath.ToSlash(rel), data: data, mode: fi.Mode().Perm(), modTime: mod})
+	}
+	return out, nil
+}
+
+func writeZip(w io.Writer, src Source, base string) error {
+	files, err := entries(src, base)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(w)
+	for _, e := range files {
+		h := &zip.FileHeader{Name: e.name, Method: zip.Deflate, Modified: e.modTime}
+		h.SetMode(e.mode)
+		fw, err := zw.CreateHeader(h)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(e.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeTarGz(w io.Writer, src Source, base string) error {
+	files, err := entries(src, base)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, e := range files {
+		h := &tar.Header{
+			Name:     e.name,
+			Mode:     int64(e.mode),
+			Size:     int64(len(e.data)),
+			ModTime:  e.modTime,
+			Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(h); err != nil {
+			return err
+		}
+		if _, err := tw.Write(e.data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
@@ -0,0 +1,83 @@
+package archive_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/archive"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/memfs"
+)
+
+func stagedProject(t *testing.T) *memfs.FS {
+	t.Helper()
+	m := memfs.New()
+	if err := m.MkdirAll("app/scripts", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.WriteFile("app/go.mod", []byte("module example.com/app\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.WriteFile("app/scripts/run.sh", []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestWrite_Zip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := archive.Write(&buf, archive.Zip, stagedProject(t), "."); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip: %v", err)
+	}
+	modes := map[string]uint32{}
+	for _, f := range zr.File {
+		modes[f.Name] = uint32(f.Mode().Perm())
+	}
+	if modes["app/go.mod"] != 0o644 || modes["app/scripts/run.sh"] != 0o755 || len(modes) != 2 {
+		t.Fatalf("entries = %v", modes)
+	}
+}
+
+func TestWrite_TarGz(t *testing.T) {
+	var buf bytes.Buffer
+	if err := archive.Write(&buf, archive.TarGz, stagedProject(t), "app"); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	var names []string
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("tar: %v", err)
+		}
+		names = append(names, h.Name)
+	}
+	if len(names) != 2 || names[0] != "go.mod" || names[1] != "scripts/run.sh" {
+		t.Fatalf("entries = %v", names)
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	for in, want := range map[string]archive.Format{"a.zip": archive.Zip, "a.tar.gz": archive.TarGz, "a.TGZ": archive.TarGz} {
+		if got, err := archive.FormatFromPath(in); err != nil || got != want {
+			t.Errorf("%s = %v, %v", in, got, err)
+		}
+	}
+	if _, err := archive.FormatFromPath("a.rar"); err == nil {
+		t.Error("expected error for .rar")
+	}
+}
@@ -1,159 +1,462 @@
 package configeditor
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"io/fs"
-	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 
-	gormmodule "github.com/nduyhai/gocraft/internal/adapters/outbound/modules/db/gorm"
-	grpcservermodule "github.com/nduyhai/gocraft/internal/adapters/outbound/modules/grpc/server"
-	chimodule "github.com/nduyhai/gocraft/internal/adapters/outbound/modules/http/chi"
-	ginmodule "github.com/nduyhai/gocraft/internal/adapters/outbound/modules/http/gin"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/config/schema"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/config/structgen"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/osfs"
 	"github.com/nduyhai/gocraft/internal/core/ports"
 	"gopkg.in/yaml.v3"
 )
 
-// Editor edits <root>/config/config.yml by merging module-specific default settings.
-// It is idempotent: existing keys are preserved; only missing keys are added.
+// EnvExample is the file, at the project root, listing every environment override.
+const EnvExample = ".env.example"
+
+// ConfigStruct is the generated file, relative to the project root, declaring env.Config.
+const ConfigStruct = "internal/platform/env/config_gen.go"
+
+// SchemaFile is the merged JSON Schema of the installed modules' config sections, relative to
+// the project root; it sits next to the config files it describes.
+const SchemaFile = "config/schema.json"
+
+// Profiles are the config overlays platform:base generates, selected at runtime by APP_ENV.
+var Profiles = []string{"local", "staging", "prod"}
+
+// Editor edits <root>/config/config.yml (or a config.<profile>.yml overlay) by merging
+// module-specific default settings. Projects generated with another config.format have
+// config/config.toml, config/config.json or, env-only, a .env.example instead; the editor
+// follows whichever it finds (Format).
+// It is idempotent: existing keys are preserved; only missing keys are added, in place, so the
+// file's comments, key order and formatting survive every `add`.
 // It tolerates missing config file by creating it when needed.
 
-type Editor struct{ root string }
+type Editor struct {
+	fs        ports.FileSystem
+	root      string
+	profile   string             // "" for config.yml
+	templates ports.TemplateRepo // optional; overridden defaults and schemas
+}
+
+func New(projectRoot string) *Editor { return NewFS(osfs.New(), projectRoot) }
 
-func New(projectRoot string) *Editor { return &Editor{root: projectRoot} }
+// NewFS returns an editor that reads and writes through the given file system.
+func NewFS(fsys ports.FileSystem, projectRoot string) *Editor {
+	return &Editor{fs: fsys, root: projectRoot}
+}
 
-func (e *Editor) path() string { return filepath.Join(e.root, "config", "config.yml") }
+// WithTemplates reads module defaults and schemas through repo, so overridden or ejected
+// templates apply; without it the modules' built-in templates are used.
+func (e *Editor) WithTemplates(repo ports.TemplateRepo) *Editor {
+	e.templates = repo
+	return e
+}
+
+// Format returns the project's config format (a ports.Config* constant): that of the config
+// file present, ports.ConfigEnv when .env.example is the configuration itself, and
+// ports.ConfigYAML for projects without config yet.
+func (e *Editor) Format() string {
+	for _, f := range []string{ports.ConfigYAML, ports.ConfigTOML, ports.ConfigJSON} {
+		if _, err := e.fs.Stat(codecFor(f).path(e.root, "")); err == nil {
+			return f
+		}
+	}
+	if b, err := e.fs.ReadFile(filepath.Join(e.root, EnvExample)); err == nil && isEnvConfig(b) {
+		return ports.ConfigEnv
+	}
+	return ports.ConfigYAML
+}
+
+func (e *Editor) codec() codec { return codecFor(e.Format()) }
+
+func (e *Editor) path() string { return e.codec().path(e.root, e.profile) }
+
+// Profile returns an editor for config/config.<name>.yml. Projects without that overlay (for
+// example those generated before profiles existed, or env-only ones) keep everything in the
+// base config, so the editor for it is returned instead.
+func (e *Editor) Profile(name string) ports.ConfigEditor {
+	c := e.codec()
+	if _, ok := c.(envCodec); ok {
+		return &Editor{fs: e.fs, root: e.root, templates: e.templates}
+	}
+	if _, err := e.fs.Stat(c.path(e.root, name)); err != nil {
+		return &Editor{fs: e.fs, root: e.root, templates: e.templates}
+	}
+	return &Editor{fs: e.fs, root: e.root, profile: name, templates: e.templates}
+}
 
-// EnsureDefaultsFor merges default config for a known module into config/config.yml.
-func (e *Editor) EnsureDefaultsFor(module string) error {
-	defaults := defaultsFor(module)
+// EnsureDefaults merges a module's default config into config/config.yml, and its
+// config/defaults.<profile>.yml.tmpl templates into the matching profile overlays.
+// Missing keys are inserted with the comments from the module's defaults templates; the rest of
+// the file is left byte for byte, and nothing is written when no key is missing.
+// The module's ports.SchemaTemplate is merged into SchemaFile, and .env.example and the typed
+// env.Config (ConfigStruct) are refreshed afterwards.
+func (e *Editor) EnsureDefaults(m ports.Module) error {
+	tmpl := e.templatesOf(m)
+	if err := e.merge(defaultsFor(m, tmpl)); err != nil {
+		return err
+	}
+	if err := e.mergeSchema(m.Name(), tmpl); err != nil {
+		return err
+	}
+	for _, p := range profileDefaults(tmpl) {
+		if err := e.Profile(p.profile).(*Editor).merge(p.data); err != nil {
+			return err
+		}
+	}
+	if err := e.writeEnvExample(); err != nil {
+		return err
+	}
+	return e.writeConfigStruct()
+}
+
+func (e *Editor) merge(defaults []byte) error {
 	if defaults == nil {
 		return nil
 	}
 	p := e.path()
-	// Read existing YAML (if any)
-	var current map[string]any
-	b, err := os.ReadFile(p)
+	b, err := e.fs.ReadFile(p)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	out, changed, err := e.codec().merge(b, defaults)
 	if err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
-			return err
+		// Never overwrite a file that does not parse; config_gen.go would go stale with it
+		return fmt.Errorf("%s: %w", p, err)
+	}
+	if !changed {
+		return nil
+	}
+	if err := e.fs.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	return e.fs.WriteFile(p, out, 0o644)
+}
+
+// Get returns the scalar at a dot-separated key of the edited file.
+func (e *Editor) Get(key string) (string, bool) {
+	b, err := e.fs.ReadFile(e.path())
+	if err != nil {
+		return "", false
+	}
+	return e.codec().get(b, strings.Split(key, "."))
+}
+
+// Set sets the scalar at a dot-separated key of the edited file, creating the file if needed.
+func (e *Editor) Set(key string, value any) error {
+	p := e.path()
+	b, err := e.fs.ReadFile(p)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	out, err := e.codec().set(b, strings.Split(key, "."), value)
+	if err != nil {
+		return fmt.Errorf("set %s in %s: %w", key, p, err)
+	}
+	if err := e.fs.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	return e.fs.WriteFile(p, out, 0o644)
+}
+
+// Convert rewrites config/config.yml and its profile overlays (or whichever format the project
+// has) in format, keeping comments where the format has them. Converting to ports.ConfigEnv
+// turns the base config into .env.example and drops the overlays: env-only deployments set their
+// values per environment.
+func (e *Editor) Convert(format string) error {
+	from := e.Format()
+	if format == from {
+		return nil
+	}
+	src, to := codecFor(from), codecFor(format)
+	profiles := append([]string{""}, Profiles...)
+	if from == ports.ConfigEnv {
+		profiles = profiles[:1]
+	}
+	for _, p := range profiles {
+		in := src.path(e.root, p)
+		b, err := e.fs.ReadFile(in)
+		if err != nil {
+			continue
 		}
-		current = make(map[string]any)
-	} else {
-		if len(b) == 0 {
-			current = make(map[string]any)
-		} else {
-			if err := yaml.Unmarshal(b, &current); err != nil {
-				// If parsing fails, do not overwrite; leave as-is (be conservative)
-				return nil
+		if format != ports.ConfigEnv || p == "" {
+			y, err := src.yaml(b)
+			if err != nil {
+				return fmt.Errorf("convert %s: %w", in, err)
+			}
+			out, _, err := to.merge(nil, y)
+			if err != nil {
+				return fmt.Errorf("convert %s: %w", in, err)
+			}
+			if err := e.fs.Remove(in); err != nil {
+				return err
+			}
+			dst := to.path(e.root, p)
+			if err := e.fs.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+				return err
+			}
+			if err := e.fs.WriteFile(dst, out, 0o644); err != nil {
+				return err
 			}
+			continue
+		}
+		if err := e.fs.Remove(in); err != nil {
+			return err
+		}
+	}
+	if err := e.writeEnvExample(); err != nil {
+		return err
+	}
+	return e.writeConfigStruct()
+}
+
+var _ ports.ConfigEditor = (*Editor)(nil)
+
+// writeEnvExample lists every config key of config.yml and its profiles as the environment
+// variable that overrides it (gorm.dsn => GORM_DSN), with its config.yml value as example.
+// Env-only projects edit .env.example directly instead.
+func (e *Editor) writeEnvExample() error {
+	c := e.codec()
+	if _, ok := c.(envCodec); ok {
+		return nil
+	}
+	basePath := c.path(e.root, "")
+	base, err := e.fs.ReadFile(basePath)
+	if err != nil {
+		return nil // no config, nothing to override
+	}
+	ext := filepath.Ext(basePath)
+	values := map[string]string{}
+	if y, err := c.yaml(base); err == nil {
+		flattenYAML(values, y)
+	}
+	keys := map[string]bool{}
+	for k := range values {
+		keys[k] = true
+	}
+	var profiles []string
+	for _, p := range Profiles {
+		b, err := e.fs.ReadFile(c.path(e.root, p))
+		if err != nil {
+			continue
 		}
+		profiles = append(profiles, p)
+		pv := map[string]string{}
+		if y, err := c.yaml(b); err == nil {
+			flattenYAML(pv, y)
+		}
+		for k := range pv {
+			keys[k] = true
+		}
+	}
+	names := make([]string, 0, len(keys))
+	for k := range keys {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Environment overrides, generated by gocraft from config/config*%s.\n", ext)
+	buf.WriteString("# Every config key can be set through its variable: gorm.dsn => GORM_DSN.\n")
+	if len(profiles) > 0 {
+		fmt.Fprintf(&buf, "\n# Config profile overlay (config/config.<APP_ENV>%s): %s\n", ext, strings.Join(profiles, ", "))
+		buf.WriteString("APP_ENV=local\n")
 	}
-	merged := mergeMaps(current, defaults)
-	out, err := yaml.Marshal(merged)
+	buf.WriteString("\n")
+	for _, k := range names {
+		fmt.Fprintf(&buf, "%s=%s\n", envName(k), values[k])
+	}
+
+	p := filepath.Join(e.root, EnvExample)
+	if cur, err := e.fs.ReadFile(p); err == nil && bytes.Equal(cur, buf.Bytes()) {
+		return nil
+	}
+	return e.fs.WriteFile(p, buf.Bytes(), 0o644)
+}
+
+// mergeSchema adds the config schema in the templates tmpl of module name, if any, to SchemaFile.
+func (e *Editor) mergeSchema(name string, tmpl fs.FS) error {
+	if tmpl == nil {
+		return nil
+	}
+	b, err := fs.ReadFile(tmpl, ports.SchemaTemplate)
+	if err != nil {
+		return nil
+	}
+	mod, err := schema.Parse(b)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", name, ports.SchemaTemplate, err)
+	}
+	p := filepath.Join(e.root, filepath.FromSlash(SchemaFile))
+	cur := &schema.Schema{}
+	if b, err := e.fs.ReadFile(p); err == nil {
+		if cur, err = schema.Parse(b); err != nil {
+			return fmt.Errorf("parse %s: %w", p, err)
+		}
+	}
+	if !cur.Merge(mod) {
+		return nil
+	}
+	out, err := cur.Marshal()
 	if err != nil {
 		return err
 	}
-	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+	if err := e.fs.MkdirAll(filepath.Dir(p), 0o755); err != nil {
 		return err
 	}
-	return os.WriteFile(p, out, 0o644)
+	return e.fs.WriteFile(p, out, 0o644)
 }
 
-var _ ports.ConfigEditor = (*Editor)(nil)
-
-// loadDefaultsFromFS tries to read a YAML defaults template from a module's embedded FS.
-// Expected path: templates/config/defaults.yml.tmpl
-// Returns nil if file doesn't exist or cannot be parsed.
-func loadDefaultsFromFS(fsys fs.FS) map[string]any {
-	const defaultsPath = "templates/config/defaults.yml.tmpl"
-	b, err := fs.ReadFile(fsys, defaultsPath)
+// writeConfigStruct regenerates ConfigStruct from config.yml and its profiles, so that it has a
+// typed field for every section the applied modules merged in, and a Validate method for the
+// rules of SchemaFile. Projects without the env package, or whose env package declares Config
+// by hand (generated before this file existed), are left alone. A config or schema that does not
+// parse is an error: the file would go stale without it.
+func (e *Editor) writeConfigStruct() error {
+	p := filepath.Join(e.root, filepath.FromSlash(ConfigStruct))
+	dir := filepath.Dir(p)
+	if _, err := e.fs.Stat(dir); err != nil {
+		return nil
+	}
+	if b, err := e.fs.ReadFile(filepath.Join(dir, "module.go")); err == nil && bytes.Contains(b, []byte("type Config struct")) {
+		return nil
+	}
+	c := e.codec()
+	names := append([]string{""}, Profiles...)
+	if _, ok := c.(envCodec); ok {
+		names = names[:1]
+	}
+	var docs [][]byte
+	for _, name := range names {
+		b, err := e.fs.ReadFile(c.path(e.root, name))
+		if err != nil {
+			continue
+		}
+		y, err := c.yaml(b)
+		if err != nil {
+			return fmt.Errorf("generate %s: %s: %w", ConfigStruct, c.path(e.root, name), err)
+		}
+		docs = append(docs, y)
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+	var sch *schema.Schema
+	if b, err := e.fs.ReadFile(filepath.Join(e.root, filepath.FromSlash(SchemaFile))); err == nil {
+		if sch, err = schema.Parse(b); err != nil {
+			return fmt.Errorf("generate %s: %s: %w", ConfigStruct, SchemaFile, err)
+		}
+	}
+	src, err := structgen.Generate("env", sch, docs...)
 	if err != nil {
+		return fmt.Errorf("generate %s: %w", ConfigStruct, err)
+	}
+	if cur, err := e.fs.ReadFile(p); err == nil && bytes.Equal(cur, src) {
 		return nil
 	}
+	return e.fs.WriteFile(p, src, 0o644)
+}
+
+// envName maps a config key to the variable Viper reads for it (dots become underscores).
+func envName(key string) string {
+	return strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+// flattenYAML collects the scalar leaves of a YAML mapping as dotted keys.
+func flattenYAML(out map[string]string, data []byte) {
 	var m map[string]any
-	if err := yaml.Unmarshal(b, &m); err != nil {
-		return nil
-	}
-	return m
-}
-
-// defaultsFor returns a nested map representing default config for a module.
-// It first tries to load defaults from the module's embedded template FS.
-// If not found, it falls back to built-in defaults to preserve existing behavior.
-func defaultsFor(module string) map[string]any {
-	switch module {
-	case "http:gin":
-		if m := loadDefaultsFromFS(ginmodule.TemplatesFS); m != nil {
-			return m
-		}
-		return map[string]any{
-			"server": map[string]any{
-				"http": map[string]any{
-					"addr": ":8080",
-				},
-			},
-		}
-	case "http:chi":
-		if m := loadDefaultsFromFS(chimodule.TemplatesFS); m != nil {
-			return m
-		}
-		return map[string]any{
-			"server": map[string]any{
-				"http": map[string]any{
-					"addr": ":8080",
-				},
-			},
-		}
-	case "grpc:server":
-		if m := loadDefaultsFromFS(grpcservermodule.TemplatesFS); m != nil {
-			return m
-		}
-		return map[string]any{
-			"server": map[string]any{
-				"grpc": map[string]any{
-					"addr":       ":9090",
-					"reflection": true,
-				},
-			},
-		}
-	case "db:gorm":
-		if m := loadDefaultsFromFS(gormmodule.TemplatesFS); m != nil {
-			return m
-		}
-		return map[string]any{
-			"gorm": map[string]any{
-				"driver":            "sqlite",
-				"dsn":               "file:app.db?_pragma=busy_timeout=5000&_pragma=journal_mode=WAL",
-				"log_level":         "warn",
-				"max_open_conns":    25,
-				"max_idle_conns":    5,
-				"conn_max_lifetime": "30m",
-			},
-		}
-	default:
-		return nil
-	}
-}
-
-// mergeMaps performs a deep merge: it sets defaults only for missing keys.
-func mergeMaps(dst, def map[string]any) map[string]any {
-	if dst == nil {
-		dst = make(map[string]any)
-	}
-	for k, v := range def {
-		if existing, ok := dst[k]; ok {
-			// if both maps, recurse
-			m1, ok1 := existing.(map[string]any)
-			m2, ok2 := v.(map[string]any)
-			if ok1 && ok2 {
-				dst[k] = mergeMaps(m1, m2)
-				continue
+	if yaml.Unmarshal(data, &m) != nil {
+		return
+	}
+	var walk func(prefix string, v any)
+	walk = func(prefix string, v any) {
+		switch t := v.(type) {
+		case map[string]any:
+			for k, sub := range t {
+				walk(prefix+k+".", sub)
 			}
-			// keep existing scalar
+		case []any:
+			// lists cannot be overridden by a single variable
+		case nil:
+			out[strings.TrimSuffix(prefix, ".")] = ""
+		default:
+			out[strings.TrimSuffix(prefix, ".")] = fmt.Sprint(t)
+		}
+	}
+	walk("", m)
+}
+
+// templatesOf returns m's template tree: through the editor's repository when it has one and
+// knows m, otherwise the module's own templates. Modules without templates return nil.
+func (e *Editor) templatesOf(m ports.Module) fs.FS {
+	if e.templates != nil {
+		if fsys, err := e.templates.FS(m.Name()); err == nil {
+			return fsys
+		}
+	}
+	if src, ok := m.(ports.TemplateSource); ok {
+		return src.Templates()
+	}
+	return nil
+}
+
+// defaultsFor returns a module's default config YAML: the ports.DefaultsTemplate of its
+// templates tmpl as written (comments included) when there is one, otherwise its Defaults()
+// encoded as YAML. Modules without defaults return nil.
+func defaultsFor(m ports.Module, tmpl fs.FS) []byte {
+	if tmpl != nil {
+		if b, err := fs.ReadFile(tmpl, ports.DefaultsTemplate); err == nil {
+			return b
+		}
+	}
+	d := m.Defaults()
+	if len(d) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(d); err != nil {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+type profileData struct {
+	profile string
+	data    []byte
+}
+
+// profileDefaults returns the config/defaults.<profile>.yml.tmpl templates of a module's
+// templates fsys.
+func profileDefaults(fsys fs.FS) []profileData {
+	if fsys == nil {
+		return nil
+	}
+	dir := path.Dir(ports.DefaultsTemplate)
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil
+	}
+	var out []profileData
+	for _, d := range entries {
+		name := path.Join(dir, d.Name())
+		profile, ok := ports.DefaultsProfile(name)
+		if !ok || profile == "" {
+			continue
+		}
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
 			continue
 		}
-		dst[k] = v
+		out = append(out, profileData{profile: profile, data: b})
 	}
-	return dst
+	return out
 }
@@ -0,0 +1,276 @@
+package configeditor_test
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	configeditor "github.com/nduyhai/gocraft/internal/adapters/outbound/config/fileeditor"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/memfs"
+	"github.com/nduyhai/gocraft/internal/core/ports"
+)
+
+// module is a third-party module the config editor knows nothing about.
+type module struct {
+	name     string
+	defaults map[string]any
+}
+
+func (m module) Name() string             { return m.name }
+func (module) Label() string              { return "" }
+func (module) Version() string            { return "" }
+func (module) Summary() string            { return "" }
+func (module) Tags() []string             { return nil }
+func (module) Requires() []string         { return nil }
+func (module) Conflicts() []string        { return nil }
+func (module) Applies(ports.Ctx) bool     { return true }
+func (module) Apply(ports.Ctx) error      { return nil }
+func (m module) Defaults() map[string]any { return m.defaults }
+
+// templated also ships a commented defaults template.
+type templated struct {
+	module
+	fsys fs.FS
+}
+
+func (t templated) Templates() fs.FS { return t.fsys }
+
+func TestEnsureDefaultsFromModule(t *testing.T) {
+	fsys := memfs.New()
+	_ = fsys.MkdirAll("app/config", 0o755)
+	_ = fsys.WriteFile("app/config/config.yml", []byte("# mine\nlogger:\n  level: info\n"), 0o644)
+	ed := configeditor.NewFS(fsys, "app")
+
+	if err := ed.EnsureDefaults(module{name: "acme:cache", defaults: map[string]any{"cache": map[string]any{"ttl": "5m"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ed.EnsureDefaults(templated{
+		module: module{name: "acme:queue", defaults: map[string]any{"queue": map[string]any{"ignored": true}}},
+		fsys:   fstest.MapFS{ports.DefaultsTemplate: {Data: []byte("# Message queue\nqueue:\n  url: amqp://localhost # broker\n")}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ed.EnsureDefaults(module{name: "acme:none"}); err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := fsys.ReadFile("app/config/config.yml")
+	want := "# mine\nlogger:\n  level: info\n\ncache:\n  ttl: 5m\n\n# Message queue\nqueue:\n  url: amqp://localhost # broker\n"
+	if string(b) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b, want)
+	}
+	if v, ok := ed.Get("cache.ttl"); !ok || v != "5m" {
+		t.Errorf("cache.ttl = %q, %v", v, ok)
+	}
+	if strings.Contains(string(b), "ignored") {
+		t.Error("Defaults() used although the module ships a defaults template")
+	}
+}
+
+func TestEnsureDefaultsRejectsBrokenConfig(t *testing.T) {
+	fsys := memfs.New()
+	_ = fsys.MkdirAll("app/config", 0o755)
+	_ = fsys.WriteFile("app/config/config.yml", []byte("logger: [oops\n"), 0o644)
+	ed := configeditor.NewFS(fsys, "app")
+
+	if err := ed.EnsureDefaults(module{name: "acme:cache", defaults: map[string]any{"cache": map[string]any{"ttl": "5m"}}}); err == nil {
+		t.Fatal("expected error for a config file that does not parse")
+	}
+	if _, err := fsys.Stat("app/internal/platform/env/config_gen.go"); err == nil {
+		t.Error("config_gen.go written from a config file that does not parse")
+	}
+}
+
+func TestEnsureDefaultsRejectsBrokenProfileAndSchema(t *testing.T) {
+	for name, file := range map[string]string{
+		"profile": "app/config/config.local.yml",
+		"schema":  "app/" + configeditor.SchemaFile,
+	} {
+		t.Run(name, func(t *testing.T) {
+			fsys := memfs.New()
+			_ = fsys.MkdirAll("app/config", 0o755)
+			_ = fsys.MkdirAll("app/internal/platform/env", 0o755)
+			_ = fsys.WriteFile("app/config/config.yml", []byte("logger:\n  level: info\n"), 0o644)
+			_ = fsys.WriteFile(file, []byte("{[oops\n"), 0o644)
+			ed := configeditor.NewFS(fsys, "app")
+
+			err := ed.EnsureDefaults(module{name: "acme:cache", defaults: map[string]any{"cache": map[string]any{"ttl": "5m"}}})
+			if err == nil || !strings.Contains(err.Error(), configeditor.ConfigStruct) {
+				t.Fatalf("err = %v, want a %s error", err, configeditor.ConfigStruct)
+			}
+		})
+	}
+}
+
+// overrides is a template repository serving replaced template trees.
+type overrides map[string]fs.FS
+
+func (o overrides) Load(string) (ports.Template, error) { return ports.Template{}, nil }
+func (o overrides) Names() []string                     { return nil }
+func (o overrides) FS(name string) (fs.FS, error)       { return o[name], nil }
+
+func TestEnsureDefaultsUsesOverriddenTemplates(t *testing.T) {
+	fsys := memfs.New()
+	_ = fsys.MkdirAll("app/config", 0o755)
+	ed := configeditor.NewFS(fsys, "app").WithTemplates(overrides{
+		"acme:queue": fstest.MapFS{
+			ports.DefaultsTemplate: {Data: []byte("queue:\n  url: amqp://override\n")},
+			ports.SchemaTemplate:   {Data: []byte(`{"properties":{"queue":{"type":"object","properties":{"url":{"type":"string"}}}}}`)},
+		},
+	})
+	if err := ed.EnsureDefaults(templated{
+		module: module{name: "acme:queue"},
+		fsys:   fstest.MapFS{ports.DefaultsTemplate: {Data: []byte("queue:\n  url: amqp://builtin\n")}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := ed.Get("queue.url"); v != "amqp://override" {
+		t.Errorf("queue.url = %q, want the overridden default", v)
+	}
+	if b, err := fsys.ReadFile("app/" + configeditor.SchemaFile); err != nil || !strings.Contains(string(b), `"queue"`) {
+		t.Errorf("schema = %s, %v", b, err)
+	}
+}
+
+func TestEnsureDefaultsProfilesAndEnvExample(t *testing.T) {
+	fsys := memfs.New()
+	_ = fsys.MkdirAll("app/config", 0o755)
+	_ = fsys.WriteFile("app/config/config.yml", []byte("logger:\n  level: info\n"), 0o644)
+	_ = fsys.WriteFile("app/config/config.local.yml", []byte("logger:\n  level: debug\n"), 0o644)
+	_ = fsys.MkdirAll("app/internal/platform/env", 0o755)
+	ed := configeditor.NewFS(fsys, "app")
+
+	err := ed.EnsureDefaults(templated{module: module{name: "acme:db"}, fsys: fstest.MapFS{
+		ports.DefaultsTemplate:           {Data: []byte("db:\n  pool: 5\n")},
+		"config/defaults.local.yml.tmpl": {Data: []byte("db:\n  dsn: file:dev.db\n")},
+		"config/defaults.prod.yml.tmpl":  {Data: []byte("db:\n  pool: 50\n")},
+		ports.SchemaTemplate:             {Data: []byte(`{"properties": {"db": {"properties": {"pool": {"type": "integer", "minimum": 1}}}}}`)},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base, _ := fsys.ReadFile("app/config/config.yml")
+	if strings.Contains(string(base), "dsn") || !strings.Contains(string(base), "pool: 5") {
+		t.Errorf("config.yml:\n%s", base)
+	}
+	if v, _ := ed.Profile("local").Get("db.dsn"); v != "file:dev.db" {
+		t.Errorf("local db.dsn = %q", v)
+	}
+	// No config.prod.yml: prod defaults fall back to config.yml, where pool already exists
+	if _, err := fsys.Stat("app/config/config.prod.yml"); err == nil {
+		t.Error("config.prod.yml created")
+	}
+
+	env, err := fsys.ReadFile("app/" + configeditor.EnvExample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"APP_ENV=local\n", "DB_DSN=\n", "DB_POOL=5\n", "LOGGER_LEVEL=info\n"} {
+		if !strings.Contains(string(env), want) {
+			t.Errorf("missing %q in .env.example:\n%s", want, env)
+		}
+	}
+
+	gen, err := fsys.ReadFile("app/" + configeditor.ConfigStruct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(gen), "DSN  string `mapstructure:\"dsn\"`") || !strings.Contains(string(gen), "DB     DBConfig") {
+		t.Errorf("config_gen.go:\n%s", gen)
+	}
+	if !strings.Contains(string(gen), "if c.DB.Pool < 1 {") {
+		t.Errorf("config_gen.go does not check the schema:\n%s", gen)
+	}
+	sch, err := fsys.ReadFile("app/" + configeditor.SchemaFile)
+	if err != nil || !strings.Contains(string(sch), `"minimum": 1`) {
+		t.Errorf("schema.json (%v):\n%s", err, sch)
+	}
+}
+
+func TestConvertAndMergeFormats(t *testing.T) {
+	queue := templated{module: module{name: "acme:queue"}, fsys: fstest.MapFS{
+		ports.DefaultsTemplate: {Data: []byte("# Message queue\nqueue:\n  url: amqp://localhost # broker\n  workers: 4\n")},
+	}}
+	cases := map[string][]string{
+		ports.ConfigTOML: {"app/config/config.toml",
+			"# mine\n[logger]\nlevel = \"info\"\n\n[server.http]\naddr = \":8080\" # listen\n\n# Message queue\n[queue]\nurl = \"amqp://localhost\" # broker\nworkers = 4\n"},
+		ports.ConfigJSON: {"app/config/config.json",
+			"{\n  \"logger\": {\n    \"level\": \"info\"\n  },\n  \"server\": {\n    \"http\": {\n      \"addr\": \":8080\"\n    }\n  },\n  \"queue\": {\n    \"url\": \"amqp://localhost\",\n    \"workers\": 4\n  }\n}\n"},
+		ports.ConfigEnv: {"app/" + configeditor.EnvExample,
+			"# logger.level\nLOGGER_LEVEL=info\n\n# server.http.addr: listen\nSERVER_HTTP_ADDR=:8080\n\n# Message queue\n# queue.url: broker\nQUEUE_URL=amqp://localhost\n\n# queue.workers\nQUEUE_WORKERS=4\n"},
+	}
+	for format, c := range cases {
+		t.Run(format, func(t *testing.T) {
+			fsys := memfs.New()
+			_ = fsys.MkdirAll("app/config", 0o755)
+			_ = fsys.WriteFile("app/config/config.yml", []byte("# mine\nlogger:\n  level: info\nserver:\n  http:\n    addr: \":8080\" # listen\n"), 0o644)
+			_ = fsys.WriteFile("app/config/config.local.yml", []byte("logger:\n  level: debug\n"), 0o644)
+			ed := configeditor.NewFS(fsys, "app")
+
+			if err := ed.Convert(format); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := fsys.Stat("app/config/config.yml"); err == nil {
+				t.Error("config.yml kept")
+			}
+			if got := ed.Format(); got != format {
+				t.Errorf("Format() = %q", got)
+			}
+			if err := ed.EnsureDefaults(queue); err != nil {
+				t.Fatal(err)
+			}
+			if err := ed.EnsureDefaults(queue); err != nil {
+				t.Fatal(err)
+			}
+			b, err := fsys.ReadFile(c[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(string(b), c[1]) {
+				t.Errorf("got:\n%s\nwant suffix:\n%s", b, c[1])
+			}
+
+			if err := ed.Set("queue.workers", 8); err != nil {
+				t.Fatal(err)
+			}
+			if v, ok := ed.Get("queue.workers"); !ok || v != "8" {
+				t.Errorf("queue.workers = %q, %v", v, ok)
+			}
+			local := ed.Profile("local")
+			if v, _ := local.Get("logger.level"); format != ports.ConfigEnv && v != "debug" {
+				t.Errorf("local logger.level = %q", v)
+			}
+			if v, _ := local.Get("logger.level"); format == ports.ConfigEnv && v != "info" {
+				t.Errorf("env-only logger.level = %q", v)
+			}
+		})
+	}
+}
+
+func TestEnsureDefaultsTOMLInPlace(t *testing.T) {
+	fsys := memfs.New()
+	_ = fsys.MkdirAll("app/config", 0o755)
+	_ = fsys.WriteFile("app/config/config.toml", []byte("[logger]\nlevel = \"info\" # mine\n\n# HTTP\n[server.http]\naddr = \":8080\"\n"), 0o644)
+	ed := configeditor.NewFS(fsys, "app")
+
+	err := ed.EnsureDefaults(module{name: "acme:log", defaults: map[string]any{
+		"logger": map[string]any{"level": "debug", "format": "json"},
+		"server": map[string]any{"http": map[string]any{"timeout": "5s"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := fsys.ReadFile("app/config/config.toml")
+	want := "[logger]\nlevel = \"info\" # mine\nformat = \"json\"\n\n# HTTP\n[server.http]\naddr = \":8080\"\ntimeout = \"5s\"\n"
+	if string(b) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b, want)
+	}
+	if err := ed.Set("logger.level", "warn"); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := fsys.ReadFile("app/config/config.toml"); !strings.HasPrefix(string(b), "[logger]\nlevel = \"warn\" # mine\n") {
+		t.Errorf("after Set:\n%s", b)
+	}
+}
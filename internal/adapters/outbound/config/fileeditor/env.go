@@ -0,0 +1,226 @@
+package configeditor
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/config/yamledit"
+	"gopkg.in/yaml.v3"
+)
+
+// envHeader starts the .env.example of env-only projects, where it is the configuration itself
+// rather than a list of overrides; the editor detects the format by it.
+const envHeader = "# Environment configuration, generated by gocraft."
+
+// envCodec edits the .env.example of env-only projects: one NAME=value line per config key, each
+// preceded by a "# dotted.key" line that keeps the structure (and the defaults' comment). Lists
+// are comma separated, their key marked "# dotted.key[]".
+type envCodec struct{}
+
+// path is the same for every profile: env-only projects have no overlays.
+func (envCodec) path(root, _ string) string { return filepath.Join(root, EnvExample) }
+
+type envEntry struct {
+	key   string // dotted config key
+	name  string // variable
+	value string // raw, as written after '='
+	list  bool
+	line  int
+}
+
+var keyComment = regexp.MustCompile(`^#\s*([A-Za-z0-9_.-]+)(\[\])?(:.*)?$`)
+
+// entries returns the variables of an .env file with the config key each stands for.
+func (envCodec) entries(lines []string) []envEntry {
+	var out []envEntry
+	for i, l := range lines {
+		t := strings.TrimSpace(l)
+		if t == "" || strings.HasPrefix(t, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(strings.TrimPrefix(t, "export "), "=")
+		if !ok {
+			continue
+		}
+		name = strings.TrimSpace(name)
+		e := envEntry{key: strings.ToLower(name), name: name, value: strings.TrimSpace(value), line: i}
+		if i > 0 {
+			if m := keyComment.FindStringSubmatch(strings.TrimSpace(lines[i-1])); m != nil && envName(m[1]) == name {
+				e.key, e.list = m[1], m[2] != ""
+			}
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+func (c envCodec) merge(src, defaults []byte) ([]byte, bool, error) {
+	def, err := mapping(defaults)
+	if err != nil || def == nil {
+		return src, false, err
+	}
+	lines := splitLines(src)
+	have := map[string]bool{}
+	for _, e := range c.entries(lines) {
+		have[e.name] = true
+	}
+	var add []string
+	envLeaves(def, nil, func(key string, k, v *yaml.Node, section []string) {
+		if have[envName(key)] {
+			return
+		}
+		add = append(add, "")
+		add = append(add, section...)
+		add = append(add, comments(k.HeadComment)...)
+		doc := "# " + key
+		if v.Kind == yaml.SequenceNode {
+			doc += "[]"
+		}
+		if lc := strings.TrimSpace(strings.TrimPrefix(v.LineComment+k.LineComment, "#")); lc != "" {
+			doc += ": " + lc
+		}
+		add = append(add, doc, envName(key)+"="+envValue(v))
+	})
+	if len(add) == 0 {
+		return src, false, nil
+	}
+	if len(lines) == 0 {
+		lines = []string{envHeader, "# The service reads no config file: set these variables (gorm.dsn => GORM_DSN)."}
+	}
+	out := append(lines, add...)
+	return []byte(strings.Join(out, "\n") + "\n"), true, nil
+}
+
+// envLeaves calls fn for each scalar or list below m, in document order. section holds the
+// comments of the sections entered just before that leaf.
+func envLeaves(m *yaml.Node, path []string, fn func(key string, k, v *yaml.Node, section []string)) {
+	var pending []string
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		k, v := m.Content[i], resolve(m.Content[i+1])
+		p := appendPath(path, k.Value)
+		if v.Kind == yaml.MappingNode && len(v.Content) > 0 {
+			head := append(pending, comments(k.HeadComment)...)
+			pending = nil
+			envLeaves(v, p, func(key string, k, v *yaml.Node, section []string) {
+				fn(key, k, v, append(head, section...))
+				head = nil
+			})
+			continue
+		}
+		fn(strings.Join(p, "."), k, v, pending)
+		pending = nil
+	}
+}
+
+// envValue renders a value for NAME=value: lists are comma separated, as Viper splits them.
+func envValue(v *yaml.Node) string {
+	var s string
+	switch v.Kind {
+	case yaml.SequenceNode:
+		parts := make([]string, 0, len(v.Content))
+		for _, c := range v.Content {
+			parts = append(parts, resolve(c).Value)
+		}
+		s = strings.Join(parts, ",")
+	case yaml.MappingNode:
+		return ""
+	default:
+		if v.ShortTag() == "!!null" {
+			return ""
+		}
+		s = v.Value
+	}
+	// Quoted when a shell sourcing the file would read it differently
+	if strings.ContainsAny(s, " \t#\"'$\\&;|<>()`*?") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func (c envCodec) get(src []byte, path []string) (string, bool) {
+	name := envName(strings.Join(path, "."))
+	for _, e := range c.entries(splitLines(src)) {
+		if e.name == name {
+			return unquoteValue(e.value), true
+		}
+	}
+	return "", false
+}
+
+func (c envCodec) set(src []byte, path []string, value any) ([]byte, error) {
+	var v yaml.Node
+	if err := v.Encode(value); err != nil {
+		return nil, err
+	}
+	if v.Kind == yaml.MappingNode {
+		return nil, fmt.Errorf("%s: sections cannot be set as one variable", strings.Join(path, "."))
+	}
+	key := strings.Join(path, ".")
+	lines := splitLines(src)
+	for _, e := range c.entries(lines) {
+		if e.name == envName(key) {
+			lines[e.line] = e.name + "=" + envValue(&v)
+			return []byte(strings.Join(lines, "\n") + "\n"), nil
+		}
+	}
+	def, err := yamledit.Set(nil, path, value)
+	if err != nil {
+		return nil, err
+	}
+	out, _, err := c.merge(src, def)
+	return out, err
+}
+
+// yaml rebuilds the nested document from the "# dotted.key" lines, typing values as YAML would.
+func (c envCodec) yaml(src []byte) ([]byte, error) {
+	var out []byte
+	for _, e := range c.entries(splitLines(src)) {
+		var v any
+		raw := e.value
+		if e.list {
+			var items []any
+			if s := unquoteValue(raw); s != "" {
+				for _, item := range strings.Split(s, ",") {
+					items = append(items, scalar(strings.TrimSpace(item)))
+				}
+			}
+			v = items
+		} else if s, err := strconv.Unquote(raw); err == nil {
+			v = s
+		} else {
+			v = scalar(raw)
+		}
+		var err error
+		if out, err = yamledit.Set(out, strings.Split(e.key, "."), v); err != nil {
+			return nil, fmt.Errorf("%s: %w", e.name, err)
+		}
+	}
+	return out, nil
+}
+
+// scalar types an unquoted value as YAML would: 25 => int, true => bool, anything else string.
+func scalar(raw string) any {
+	var v any
+	if err := yaml.Unmarshal([]byte(raw), &v); err != nil {
+		return raw
+	}
+	switch v.(type) {
+	case map[string]any, []any:
+		return raw
+	}
+	return v
+}
+
+func unquoteValue(s string) string {
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+	return s
+}
+
+// isEnvConfig reports whether an .env.example is an env-only project's configuration.
+func isEnvConfig(b []byte) bool { return bytes.HasPrefix(b, []byte(envHeader)) }
@@ -0,0 +1,247 @@
+package configeditor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/config/yamledit"
+	"github.com/nduyhai/gocraft/internal/core/ports"
+	"gopkg.in/yaml.v3"
+)
+
+// codec reads and edits one config file format. Module defaults always arrive as YAML.
+type codec interface {
+	// path returns the file holding profile ("" for the base config).
+	path(root, profile string) string
+	// merge adds the keys of the YAML defaults that src lacks; it reports whether src changed.
+	merge(src, defaults []byte) ([]byte, bool, error)
+	get(src []byte, path []string) (string, bool)
+	set(src []byte, path []string, value any) ([]byte, error)
+	// yaml returns the document as YAML, for .env.example, the config struct and validation.
+	yaml(src []byte) ([]byte, error)
+}
+
+func codecFor(format string) codec {
+	switch format {
+	case ports.ConfigTOML:
+		return tomlCodec{}
+	case ports.ConfigJSON:
+		return jsonCodec{}
+	case ports.ConfigEnv:
+		return envCodec{}
+	}
+	return yamlCodec{}
+}
+
+func configFile(root, profile, ext string) string {
+	name := "config"
+	if profile != "" {
+		name += "." + profile
+	}
+	return filepath.Join(root, "config", name+ext)
+}
+
+// Decode parses a config file of any supported format, chosen by its name, into the generic
+// form yaml.Unmarshal produces.
+func Decode(name string, src []byte) (any, error) {
+	var c codec = yamlCodec{}
+	switch {
+	case strings.HasSuffix(name, ".toml"):
+		c = tomlCodec{}
+	case strings.HasSuffix(name, ".json"):
+		c = jsonCodec{}
+	case strings.HasPrefix(filepath.Base(name), ".env"):
+		c = envCodec{}
+	}
+	y, err := c.yaml(src)
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	if err := yaml.Unmarshal(y, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+type yamlCodec struct{}
+
+func (yamlCodec) path(root, profile string) string { return configFile(root, profile, ".yml") }
+func (yamlCodec) merge(src, defaults []byte) ([]byte, bool, error) {
+	return yamledit.Merge(src, defaults)
+}
+func (yamlCodec) get(src []byte, path []string) (string, bool) { return yamledit.Get(src, path) }
+func (yamlCodec) set(src []byte, path []string, value any) ([]byte, error) {
+	return yamledit.Set(src, path, value)
+}
+func (yamlCodec) yaml(src []byte) ([]byte, error) { return src, nil }
+
+// jsonCodec edits config/config.json. JSON is YAML, so documents are edited as yaml.Node trees,
+// which keep the key order, and written back as indented JSON.
+type jsonCodec struct{}
+
+func (jsonCodec) path(root, profile string) string { return configFile(root, profile, ".json") }
+
+func (jsonCodec) merge(src, defaults []byte) ([]byte, bool, error) {
+	def, err := mapping(defaults)
+	if err != nil || def == nil {
+		return src, false, err
+	}
+	dst, err := mapping(src)
+	if err != nil {
+		return nil, false, err
+	}
+	if dst == nil {
+		dst = &yaml.Node{Kind: yaml.MappingNode}
+	}
+	if !addMissing(dst, def) {
+		return src, false, nil
+	}
+	out, err := encodeJSON(dst)
+	return out, err == nil, err
+}
+
+func (jsonCodec) get(src []byte, path []string) (string, bool) { return yamledit.Get(src, path) }
+
+func (jsonCodec) set(src []byte, path []string, value any) ([]byte, error) {
+	root, err := mapping(src)
+	if err != nil {
+		return nil, err
+	}
+	if root == nil {
+		root = &yaml.Node{Kind: yaml.MappingNode}
+	}
+	var v yaml.Node
+	if err := v.Encode(value); err != nil {
+		return nil, err
+	}
+	if err := setNode(root, path, &v); err != nil {
+		return nil, err
+	}
+	return encodeJSON(root)
+}
+
+// setNode sets the value at path below m, creating the mappings on the way.
+func setNode(m *yaml.Node, path []string, v *yaml.Node) error {
+	for i, key := range path {
+		n := lookup(m, key)
+		if i == len(path)-1 {
+			switch {
+			case n == nil:
+				m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, v)
+			case n.Kind != yaml.ScalarNode:
+				return fmt.Errorf("%s is not a single value", strings.Join(path, "."))
+			default:
+				*n = *v
+			}
+			return nil
+		}
+		if n == nil {
+			n = &yaml.Node{Kind: yaml.MappingNode}
+			m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, n)
+		}
+		if n.Kind != yaml.MappingNode {
+			return fmt.Errorf("%s is not a section", strings.Join(path[:i+1], "."))
+		}
+		m = n
+	}
+	return nil
+}
+
+func (jsonCodec) yaml(src []byte) ([]byte, error) { return src, nil }
+
+// mapping parses a YAML (or JSON) document and returns its top-level mapping, nil when empty.
+func mapping(src []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("top level is not a mapping")
+	}
+	return doc.Content[0], nil
+}
+
+func lookup(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// addMissing appends the pairs of def that dst lacks, recursing into mappings on both sides.
+func addMissing(dst, def *yaml.Node) bool {
+	changed := false
+	for i := 0; i+1 < len(def.Content); i += 2 {
+		k, v := def.Content[i], def.Content[i+1]
+		switch cur := lookup(dst, k.Value); {
+		case cur == nil:
+			dst.Content = append(dst.Content, k, v)
+			changed = true
+		case cur.Kind == yaml.MappingNode && v.Kind == yaml.MappingNode:
+			if addMissing(cur, v) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+func encodeJSON(n *yaml.Node) ([]byte, error) {
+	var compact bytes.Buffer
+	if err := writeJSON(&compact, n); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, compact.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+func writeJSON(b *bytes.Buffer, n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return writeJSON(b, n.Alias)
+	case yaml.MappingNode, yaml.SequenceNode:
+		open, end, step := byte('{'), byte('}'), 2
+		if n.Kind == yaml.SequenceNode {
+			open, end, step = '[', ']', 1
+		}
+		b.WriteByte(open)
+		for i := 0; i < len(n.Content); i += step {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if step == 2 {
+				k, _ := json.Marshal(n.Content[i].Value)
+				b.Write(k)
+				b.WriteByte(':')
+			}
+			if err := writeJSON(b, n.Content[i+step-1]); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(end)
+		return nil
+	}
+	var v any
+	if err := n.Decode(&v); err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	b.Write(data)
+	return nil
+}
@@ -0,0 +1,498 @@
+package configeditor
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// tomlCodec edits config/config.toml. Missing keys are inserted as text at the end of their
+// table and missing sections appended as new tables, so comments and order are kept. Documents
+// that cannot be edited that way (inline tables, dotted keys) are re-encoded instead.
+type tomlCodec struct{}
+
+func (tomlCodec) path(root, profile string) string { return configFile(root, profile, ".toml") }
+
+func (tomlCodec) decode(src []byte) (map[string]any, error) {
+	m := map[string]any{}
+	if err := toml.Unmarshal(src, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c tomlCodec) yaml(src []byte) ([]byte, error) {
+	m, err := c.decode(src)
+	if err != nil || len(m) == 0 {
+		return nil, err
+	}
+	return encodeYAML(m)
+}
+
+func (c tomlCodec) get(src []byte, path []string) (string, bool) {
+	m, err := c.decode(src)
+	if err != nil {
+		return "", false
+	}
+	v, ok := walkMap(m, path)
+	switch v.(type) {
+	case map[string]any, []any, nil:
+		return "", false
+	}
+	return fmt.Sprint(v), ok
+}
+
+func (c tomlCodec) merge(src, defaults []byte) ([]byte, bool, error) {
+	def, err := mapping(defaults)
+	if err != nil || def == nil {
+		return src, false, err
+	}
+	cur, err := c.decode(src)
+	if err != nil {
+		return nil, false, err
+	}
+	ed := &tomlEdit{lines: splitLines(src), inserts: map[int][]string{}}
+	ed.walk(def, cur, nil)
+	if !ed.changed {
+		return src, false, nil
+	}
+	out := ed.result()
+	if _, err := c.decode(out); err == nil {
+		return out, true, nil
+	}
+	// Re-encode: comments are lost, values kept
+	var d map[string]any
+	if err := yaml.Unmarshal(defaults, &d); err != nil {
+		return nil, false, err
+	}
+	mergeMaps(cur, d)
+	out, err = toml.Marshal(cur)
+	return out, err == nil, err
+}
+
+func (c tomlCodec) set(src []byte, path []string, value any) ([]byte, error) {
+	var v yaml.Node
+	if err := v.Encode(value); err != nil {
+		return nil, err
+	}
+	val, ok := tomlValue(&v)
+	if !ok {
+		return nil, fmt.Errorf("%s: TOML has no null value", strings.Join(path, "."))
+	}
+	cur, err := c.decode(src)
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := walkMap(cur, path); !exists {
+		var n yaml.Node
+		for i := len(path) - 1; i >= 0; i-- {
+			if i == len(path)-1 {
+				n = yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{{Kind: yaml.ScalarNode, Value: path[i]}, &v}}
+				continue
+			}
+			inner := n
+			n = yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{{Kind: yaml.ScalarNode, Value: path[i]}, &inner}}
+		}
+		def, err := yaml.Marshal(&n)
+		if err != nil {
+			return nil, err
+		}
+		out, _, err := c.merge(src, def)
+		return out, err
+	}
+
+	lines := splitLines(src)
+	if start, end, ok := tableRange(lines, path[:len(path)-1]); ok {
+		for i := start; i < end; i++ {
+			k, rest, found := strings.Cut(lines[i], "=")
+			if !found || isComment(lines[i]) || unquoteKey(strings.TrimSpace(k)) != path[len(path)-1] {
+				continue
+			}
+			comment, ok := trailingComment(rest)
+			if !ok {
+				break
+			}
+			lines[i] = strings.TrimRight(k, " \t") + " = " + val + comment
+			out := []byte(strings.Join(lines, "\n") + "\n")
+			if _, err := c.decode(out); err == nil {
+				return out, nil
+			}
+			break
+		}
+	}
+	// Dotted keys, inline tables or multi-line values: re-encode
+	parent := cur
+	for _, p := range path[:len(path)-1] {
+		next, ok := parent[p].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a table", p)
+		}
+		parent = next
+	}
+	parent[path[len(path)-1]] = value
+	return toml.Marshal(cur)
+}
+
+// tomlEdit collects the text insertions merge makes.
+type tomlEdit struct {
+	lines    []string
+	inserts  map[int][]string // lines to insert before lines[i]
+	appendix []string         // new tables, at the end of the file
+	changed  bool
+}
+
+func (e *tomlEdit) walk(def *yaml.Node, cur map[string]any, prefix []string) {
+	var pairs []string
+	var tables []*yaml.Node
+	for i := 0; i+1 < len(def.Content); i += 2 {
+		k, v := def.Content[i], resolve(def.Content[i+1])
+		if existing, ok := cur[k.Value]; ok {
+			if sub, isMap := existing.(map[string]any); isMap && v.Kind == yaml.MappingNode {
+				e.walk(v, sub, appendPath(prefix, k.Value))
+			}
+			continue
+		}
+		if v.Kind == yaml.MappingNode && len(v.Content) > 0 {
+			tables = append(tables, k, v)
+			continue
+		}
+		if line, ok := tomlPair(k, v); ok {
+			pairs = append(pairs, comments(k.HeadComment)...)
+			pairs = append(pairs, line)
+		}
+	}
+	if len(pairs) > 0 {
+		e.changed = true
+		if at, ok := tableEnd(e.lines, prefix); ok {
+			e.inserts[at] = append(e.inserts[at], pairs...)
+		} else {
+			// The table only exists through its sub-tables so far
+			e.appendix = append(e.appendix, "", tableHeader(prefix))
+			e.appendix = append(e.appendix, pairs...)
+		}
+	}
+	for i := 0; i+1 < len(tables); i += 2 {
+		e.changed = true
+		e.appendix = append(e.appendix, tomlTable(appendPath(prefix, tables[i].Value), tables[i], tables[i+1])...)
+	}
+}
+
+func (e *tomlEdit) result() []byte {
+	var out []string
+	for i, l := range e.lines {
+		out = append(out, e.inserts[i]...)
+		out = append(out, l)
+	}
+	out = append(out, e.inserts[len(e.lines)]...)
+	out = append(out, e.appendix...)
+	for len(out) > 0 && strings.TrimSpace(out[0]) == "" {
+		out = out[1:]
+	}
+	return []byte(strings.Join(out, "\n") + "\n")
+}
+
+// tomlTable renders a section as a [table] with its pairs, followed by its sub-tables. A
+// section holding only sub-tables gets no header of its own.
+func tomlTable(path []string, k, v *yaml.Node) []string {
+	var pairs []string
+	var subs []*yaml.Node
+	for i := 0; i+1 < len(v.Content); i += 2 {
+		sk, sv := v.Content[i], resolve(v.Content[i+1])
+		if sv.Kind == yaml.MappingNode && len(sv.Content) > 0 {
+			subs = append(subs, sk, sv)
+			continue
+		}
+		if line, ok := tomlPair(sk, sv); ok {
+			pairs = append(pairs, comments(sk.HeadComment)...)
+			pairs = append(pairs, line)
+		}
+	}
+	out := append([]string{""}, comments(k.HeadComment)...)
+	if len(pairs) > 0 {
+		h := tableHeader(path)
+		if k.LineComment != "" {
+			h += " " + k.LineComment
+		}
+		out = append(out, h)
+		out = append(out, pairs...)
+	}
+	for i := 0; i+1 < len(subs); i += 2 {
+		sub := tomlTable(appendPath(path, subs[i].Value), subs[i], subs[i+1])
+		if len(pairs) == 0 && i == 0 {
+			sub = sub[1:] // keep the parent's comment right above the first table
+		}
+		out = append(out, sub...)
+	}
+	return out
+}
+
+func tomlPair(k, v *yaml.Node) (string, bool) {
+	val, ok := tomlValue(v)
+	if !ok {
+		return "", false
+	}
+	line := tomlKey(k.Value) + " = " + val
+	if c := v.LineComment + k.LineComment; c != "" {
+		line += " " + c
+	}
+	return line, true
+}
+
+// tomlValue renders a YAML value as a TOML value; null has no TOML form.
+func tomlValue(n *yaml.Node) (string, bool) {
+	n = resolve(n)
+	switch n.Kind {
+	case yaml.SequenceNode, yaml.MappingNode:
+		var parts []string
+		step := 1
+		if n.Kind == yaml.MappingNode {
+			step = 2
+		}
+		for i := 0; i+step-1 < len(n.Content); i += step {
+			s, ok := tomlValue(n.Content[i+step-1])
+			if !ok {
+				continue
+			}
+			if step == 2 {
+				s = tomlKey(n.Content[i].Value) + " = " + s
+			}
+			parts = append(parts, s)
+		}
+		if step == 2 {
+			return "{" + strings.Join(parts, ", ") + "}", true
+		}
+		return "[" + strings.Join(parts, ", ") + "]", true
+	}
+	var v any
+	if err := n.Decode(&v); err != nil {
+		return strconv.Quote(n.Value), true
+	}
+	switch t := v.(type) {
+	case nil:
+		return "", false
+	case string:
+		return strconv.Quote(t), true
+	case bool:
+		return strconv.FormatBool(t), true
+	case int:
+		return strconv.Itoa(t), true
+	case int64:
+		return strconv.FormatInt(t, 10), true
+	case uint64:
+		return strconv.FormatUint(t, 10), true
+	case float64:
+		switch {
+		case math.IsNaN(t):
+			return "nan", true
+		case math.IsInf(t, 1):
+			return "inf", true
+		case math.IsInf(t, -1):
+			return "-inf", true
+		}
+		s := strconv.FormatFloat(t, 'f', -1, 64)
+		if !strings.ContainsAny(s, ".eE") {
+			s += ".0"
+		}
+		return s, true
+	}
+	return strconv.Quote(fmt.Sprint(v)), true
+}
+
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func tomlKey(k string) string {
+	if bareKey.MatchString(k) {
+		return k
+	}
+	return strconv.Quote(k)
+}
+
+func unquoteKey(k string) string {
+	if s, err := strconv.Unquote(k); err == nil {
+		return s
+	}
+	return strings.Trim(k, "'")
+}
+
+func tableHeader(path []string) string {
+	keys := make([]string, len(path))
+	for i, p := range path {
+		keys[i] = tomlKey(p)
+	}
+	return "[" + strings.Join(keys, ".") + "]"
+}
+
+func isHeader(l string) bool  { return strings.HasPrefix(strings.TrimSpace(l), "[") }
+func isComment(l string) bool { return strings.HasPrefix(strings.TrimSpace(l), "#") }
+func isBlank(l string) bool   { return strings.TrimSpace(l) == "" }
+
+// headerPath returns the dotted name of a [table] header line, "" for [[array]] headers.
+func headerPath(l string) string {
+	t := strings.TrimSpace(l)
+	if strings.HasPrefix(t, "[[") {
+		return ""
+	}
+	end := strings.Index(t, "]")
+	if end < 0 {
+		return ""
+	}
+	parts := strings.Split(t[1:end], ".")
+	for i, p := range parts {
+		parts[i] = unquoteKey(strings.TrimSpace(p))
+	}
+	return strings.Join(parts, ".")
+}
+
+// tableRange returns the lines holding the pairs of table path: the root pairs before the first
+// header, or the lines after [path] up to the next header. It fails when [path] has no header.
+func tableRange(lines []string, path []string) (start, end int, ok bool) {
+	if len(path) > 0 {
+		name, found := strings.Join(path, "."), false
+		for i, l := range lines {
+			if isHeader(l) && headerPath(l) == name {
+				start, found = i+1, true
+				break
+			}
+		}
+		if !found {
+			return 0, 0, false
+		}
+	}
+	end = len(lines)
+	for i := start; i < len(lines); i++ {
+		if isHeader(lines[i]) {
+			end = i
+			break
+		}
+	}
+	return start, end, true
+}
+
+// tableEnd returns where new pairs of table path go: after its last pair, before the comment
+// and blank lines leading into the next table.
+func tableEnd(lines []string, path []string) (int, bool) {
+	start, end, ok := tableRange(lines, path)
+	if !ok {
+		return 0, false
+	}
+	at := end
+	if end < len(lines) {
+		for at > start && isComment(lines[at-1]) {
+			at--
+		}
+	}
+	for at > start && isBlank(lines[at-1]) {
+		at--
+	}
+	return at, true
+}
+
+// trailingComment returns the spacing and comment after a single-line value, false for values
+// spanning several lines.
+func trailingComment(rest string) (string, bool) {
+	r := strings.TrimSpace(rest)
+	if strings.HasPrefix(r, `"""`) || strings.HasPrefix(r, "'''") {
+		return "", false
+	}
+	i := 0
+	switch {
+	case strings.HasPrefix(r, `"`):
+		for i = 1; i < len(r) && r[i] != '"'; i++ {
+			if r[i] == '\\' {
+				i++
+			}
+		}
+		i++
+	case strings.HasPrefix(r, "'"):
+		i = strings.Index(r[1:], "'") + 2
+	case strings.HasPrefix(r, "[") || strings.HasPrefix(r, "{"):
+		if strings.Count(r, "[")+strings.Count(r, "{") != strings.Count(r, "]")+strings.Count(r, "}") {
+			return "", false
+		}
+		i = strings.LastIndexAny(r, "]}") + 1
+	}
+	if i > len(r) {
+		return "", false
+	}
+	if j := strings.Index(r[i:], "#"); j >= 0 {
+		return r[i:], true
+	}
+	return "", true
+}
+
+func comments(head string) []string {
+	if head == "" {
+		return nil
+	}
+	var out []string
+	for _, l := range strings.Split(head, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func splitLines(src []byte) []string {
+	if len(bytes.TrimSpace(src)) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimRight(string(src), "\n"), "\n")
+}
+
+func appendPath(path []string, key string) []string {
+	return append(append([]string{}, path...), key)
+}
+
+func resolve(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	return n
+}
+
+func walkMap(m map[string]any, path []string) (any, bool) {
+	var cur any = m
+	for _, p := range path {
+		mm, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = mm[p]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// mergeMaps adds the keys of src that dst lacks, recursing into maps on both sides.
+func mergeMaps(dst, src map[string]any) {
+	for k, v := range src {
+		cur, ok := dst[k]
+		if !ok {
+			dst[k] = v
+			continue
+		}
+		cm, ok1 := cur.(map[string]any)
+		sm, ok2 := v.(map[string]any)
+		if ok1 && ok2 {
+			mergeMaps(cm, sm)
+		}
+	}
+}
+
+func encodeYAML(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
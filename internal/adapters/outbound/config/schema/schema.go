@@ -0,0 +1,301 @@
+// Package schema reads the JSON Schema subset modules use to describe their config section,
+// merges the schemas of the installed modules and validates config documents against them.
+//
+// Supported keywords: type (string, integer, number, boolean, object, array), properties, items,
+// enum, minimum, maximum, pattern and format (hostport, duration). Other keywords are kept but
+// ignored.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Draft is the $schema of the merged project schema.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema node.
+type Schema struct {
+	Draft       string             `json:"$schema,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Enum        []any              `json:"enum,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	Pattern     string             `json:"pattern,omitempty"`
+	Format      string             `json:"format,omitempty"`
+}
+
+// Parse decodes a JSON schema and checks its patterns compile.
+func Parse(b []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+	if err := s.compile(""); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (s *Schema) compile(path string) error {
+	if s.Pattern != "" {
+		if _, err := regexp.Compile(s.Pattern); err != nil {
+			return fmt.Errorf("%s: pattern: %w", orRoot(path), err)
+		}
+	}
+	for k, p := range s.Properties {
+		if err := p.compile(join(path, k)); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.compile(path + "[]")
+	}
+	return nil
+}
+
+// Marshal encodes s as indented JSON with the $schema draft set.
+func (s *Schema) Marshal() ([]byte, error) {
+	out := *s
+	out.Draft = Draft
+	b, err := json.MarshalIndent(&out, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// Merge adds o to s: properties are merged recursively, and keywords s does not set yet are
+// taken from o. It reports whether s changed.
+func (s *Schema) Merge(o *Schema) bool {
+	changed := false
+	set := func(dst *string, v string) {
+		if *dst == "" && v != "" {
+			*dst, changed = v, true
+		}
+	}
+	set(&s.Description, o.Description)
+	set(&s.Type, o.Type)
+	set(&s.Pattern, o.Pattern)
+	set(&s.Format, o.Format)
+	if s.Enum == nil && o.Enum != nil {
+		s.Enum, changed = o.Enum, true
+	}
+	if s.Minimum == nil && o.Minimum != nil {
+		s.Minimum, changed = o.Minimum, true
+	}
+	if s.Maximum == nil && o.Maximum != nil {
+		s.Maximum, changed = o.Maximum, true
+	}
+	if o.Items != nil {
+		if s.Items == nil {
+			s.Items, changed = &Schema{}, true
+		}
+		if s.Items.Merge(o.Items) {
+			changed = true
+		}
+	}
+	for k, p := range o.Properties {
+		if s.Properties == nil {
+			s.Properties = map[string]*Schema{}
+		}
+		if s.Properties[k] == nil {
+			s.Properties[k], changed = &Schema{}, true
+		}
+		if s.Properties[k].Merge(p) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Lookup returns the schema of a config key, nil when it is not described.
+func (s *Schema) Lookup(path []string) *Schema {
+	for _, p := range path {
+		if s = s.Properties[p]; s == nil {
+			return nil
+		}
+	}
+	return s
+}
+
+// Issue is a value that does not match the schema.
+type Issue struct {
+	Path    string // dotted config key
+	Message string
+}
+
+func (i Issue) String() string { return i.Path + ": " + i.Message }
+
+// Validate checks a decoded config document (as produced by yaml.Unmarshal into map[string]any)
+// and returns the issues sorted by key. Keys the schema does not describe are accepted.
+func (s *Schema) Validate(doc any) []Issue {
+	var out []Issue
+	s.check("", doc, &out)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+func (s *Schema) check(path string, v any, out *[]Issue) {
+	if v == nil {
+		return // unset: defaults and environment overrides apply
+	}
+	add := func(format string, args ...any) {
+		*out = append(*out, Issue{Path: orRoot(path), Message: fmt.Sprintf(format, args...)})
+	}
+	if s.Type != "" && !hasType(v, s.Type) {
+		add("must be %s, got %s", article(s.Type), describe(v))
+		return
+	}
+	switch t := v.(type) {
+	case map[string]any:
+		for k, sub := range t {
+			if p := s.Properties[k]; p != nil {
+				p.check(join(path, k), sub, out)
+			}
+		}
+		return
+	case []any:
+		if s.Items != nil {
+			for i, item := range t {
+				s.Items.check(fmt.Sprintf("%s[%d]", path, i), item, out)
+			}
+		}
+		return
+	}
+	if len(s.Enum) > 0 && !inEnum(v, s.Enum) {
+		add("must be one of %s, got %v", enumList(s.Enum), v)
+	}
+	if n, ok := number(v); ok {
+		if s.Minimum != nil && n < *s.Minimum {
+			add("must be >= %v, got %v", *s.Minimum, v)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			add("must be <= %v, got %v", *s.Maximum, v)
+		}
+	}
+	str, isStr := v.(string)
+	if !isStr {
+		return
+	}
+	if s.Pattern != "" {
+		if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(str) {
+			add("must match %s, got %q", s.Pattern, str)
+		}
+	}
+	if err := CheckFormat(s.Format, str); err != nil {
+		add("%v", err)
+	}
+}
+
+// CheckFormat checks a string against a format keyword; unknown formats pass.
+func CheckFormat(format, v string) error {
+	switch format {
+	case "hostport":
+		if _, _, err := net.SplitHostPort(v); err != nil {
+			return fmt.Errorf("must be host:port, got %q", v)
+		}
+	case "duration":
+		if _, err := time.ParseDuration(v); err != nil {
+			return fmt.Errorf("must be a duration such as 30s, got %q", v)
+		}
+	}
+	return nil
+}
+
+func hasType(v any, typ string) bool {
+	switch typ {
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "integer":
+		n, ok := number(v)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := number(v)
+		return ok
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	}
+	return true
+}
+
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func inEnum(v any, enum []any) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func enumList(enum []any) string {
+	parts := make([]string, len(enum))
+	for i, e := range enum {
+		parts[i] = fmt.Sprint(e)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func describe(v any) string {
+	switch v.(type) {
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case map[string]any:
+		return "an object"
+	case []any:
+		return "an array"
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func article(typ string) string {
+	if typ == "integer" || typ == "object" || typ == "array" {
+		return "an " + typ
+	}
+	return "a " + typ
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func orRoot(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
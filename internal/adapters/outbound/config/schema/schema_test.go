@@ -0,0 +1,80 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/config/schema"
+	"gopkg.in/yaml.v3"
+)
+
+const gormSchema = `{
+  "properties": {
+    "gorm": {
+      "type": "object",
+      "properties": {
+        "driver": {"type": "string", "enum": ["postgres", "mysql", "sqlite"]},
+        "max_open_conns": {"type": "integer", "minimum": 0},
+        "conn_max_lifetime": {"type": "string", "format": "duration"}
+      }
+    }
+  }
+}`
+
+const httpSchema = `{
+  "properties": {
+    "server": {"properties": {"http": {"properties": {"addr": {"type": "string", "format": "hostport"}}}}}
+  }
+}`
+
+func TestMergeAndValidate(t *testing.T) {
+	s, err := schema.Parse([]byte(gormSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := schema.Parse([]byte(httpSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Merge(h) || s.Merge(h) {
+		t.Error("Merge should report a change once")
+	}
+
+	var doc any
+	_ = yaml.Unmarshal([]byte(`
+gorm:
+  driver: oracle
+  max_open_conns: -1
+  conn_max_lifetime: soon
+  extra: kept
+server:
+  http:
+    addr: "8080"
+logger:
+  level: info
+`), &doc)
+	var got []string
+	for _, i := range s.Validate(doc) {
+		got = append(got, i.String())
+	}
+	want := []string{
+		`gorm.conn_max_lifetime: must be a duration such as 30s, got "soon"`,
+		"gorm.driver: must be one of postgres, mysql, sqlite, got oracle",
+		"gorm.max_open_conns: must be >= 0, got -1",
+		`server.http.addr: must be host:port, got "8080"`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("issues:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	_ = yaml.Unmarshal([]byte("gorm:\n  max_open_conns: lots\n"), &doc)
+	if issues := s.Validate(doc); len(issues) != 1 || issues[0].Message != `must be an integer, got string "lots"` {
+		t.Errorf("type issue = %v", issues)
+	}
+}
+
+func TestParseRejectsBadPattern(t *testing.T) {
+	if _, err := schema.Parse([]byte(`{"properties": {"a": {"pattern": "("}}}`)); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,356 @@
+// Package structgen generates typed Go configuration structs, with mapstructure tags, from the
+// YAML config files that module defaults are merged into.
+package structgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/config/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// Header is the first line of every generated file.
+const Header = "// Code generated by gocraft from config/config*.yml. DO NOT EDIT."
+
+// Generate returns the source of package pkg declaring Config, with one named struct per nested
+// section (server.http => ServerHTTPConfig). Later documents (profile overlays) add keys to the
+// earlier ones; fields keep the order of the first document that sets them.
+// Scalars map to string, int, float64, bool or time.Duration ("30m"), lists of scalars to slices,
+// and values whose type differs between documents to any.
+// Config also gets a Validate method checking the enum, minimum, maximum, pattern and hostport
+// rules that sch (which may be nil) sets on its fields.
+func Generate(pkg string, sch *schema.Schema, docs ...[]byte) ([]byte, error) {
+	root := newObject()
+	for _, d := range docs {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(d, &doc); err != nil {
+			return nil, err
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		if n := doc.Content[0]; n.Kind == yaml.MappingNode {
+			root.add(n)
+		}
+	}
+
+	var body bytes.Buffer
+	imports := map[string]bool{}
+	writeStruct(&body, "Config", "", root, imports)
+	writeValidate(&body, root, sch, imports)
+
+	var buf bytes.Buffer
+	buf.WriteString(Header + "\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	if len(imports) > 0 {
+		names := make([]string, 0, len(imports))
+		for name := range imports {
+			names = append(names, strconv.Quote(name))
+		}
+		sort.Strings(names)
+		fmt.Fprintf(&buf, "import (\n%s\n)\n\n", strings.Join(names, "\n"))
+	}
+	buf.Write(body.Bytes())
+	return format.Source(buf.Bytes())
+}
+
+type object struct {
+	fields []*field
+	byKey  map[string]*field
+}
+
+type field struct {
+	key  string
+	typ  string  // Go type of a scalar, list or map; "" for a section
+	obj  *object // section
+	null bool    // only seen as null, any later type wins
+}
+
+func newObject() *object { return &object{byKey: map[string]*field{}} }
+
+func (o *object) add(m *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		k, v := m.Content[i].Value, resolve(m.Content[i+1])
+		f := o.byKey[k]
+		if f == nil {
+			f = &field{key: k, null: true}
+			o.byKey[k] = f
+			o.fields = append(o.fields, f)
+		}
+		f.merge(v)
+	}
+}
+
+func (f *field) merge(v *yaml.Node) {
+	if v.Kind == yaml.MappingNode && len(v.Content) > 0 {
+		switch {
+		case f.obj != nil:
+		case f.null:
+			f.obj, f.typ = newObject(), ""
+		default:
+			f.typ = "any"
+			return
+		}
+		f.null = false
+		f.obj.add(v)
+		return
+	}
+	t := typeOf(v)
+	switch {
+	case t == "":
+		if f.null {
+			f.typ = "string"
+		}
+		return
+	case f.null:
+		f.typ = t
+	case f.obj != nil:
+		f.obj, f.typ = nil, "any"
+	case f.typ != t:
+		if numeric(f.typ) && numeric(t) {
+			f.typ = "float64"
+		} else {
+			f.typ = "any"
+		}
+	}
+	f.null = false
+}
+
+func numeric(t string) bool { return t == "int" || t == "float64" }
+
+// typeOf returns the Go type for a non-section value, "" for null.
+func typeOf(v *yaml.Node) string {
+	switch v.Kind {
+	case yaml.MappingNode:
+		return "map[string]any"
+	case yaml.SequenceNode:
+		elem := ""
+		for _, c := range v.Content {
+			t := typeOf(resolve(c))
+			if t == "" || strings.HasPrefix(t, "[]") || strings.HasPrefix(t, "map") || (elem != "" && t != elem) {
+				return "[]any"
+			}
+			elem = t
+		}
+		if elem == "" {
+			return "[]string"
+		}
+		return "[]" + elem
+	}
+	switch v.ShortTag() {
+	case "!!null":
+		return ""
+	case "!!int":
+		return "int"
+	case "!!float":
+		return "float64"
+	case "!!bool":
+		return "bool"
+	}
+	if isDuration(v.Value) {
+		return "time.Duration"
+	}
+	return "string"
+}
+
+// isDuration reports whether s is a unit-suffixed duration such as 5s or 1h30m.
+func isDuration(s string) bool {
+	if s == "" || !unicode.IsLetter(rune(s[len(s)-1])) {
+		return false
+	}
+	_, err := time.ParseDuration(s)
+	return err == nil
+}
+
+func resolve(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	return n
+}
+
+// writeStruct writes the struct type for o, then the types of its sections depth first.
+func writeStruct(buf *bytes.Buffer, name, path string, o *object, imports map[string]bool) {
+	if path == "" {
+		fmt.Fprintf(buf, "// %s is the application configuration.\n", name)
+	} else {
+		fmt.Fprintf(buf, "// %s is the %s section.\n", name, path)
+	}
+	fmt.Fprintf(buf, "type %s struct {\n", name)
+	type section struct {
+		name, path string
+		obj        *object
+	}
+	var sections []section
+	for _, f := range o.fields {
+		typ := f.typ
+		if f.obj != nil {
+			typ = strings.TrimSuffix(name, "Config") + GoName(f.key) + "Config"
+			p := f.key
+			if path != "" {
+				p = path + "." + f.key
+			}
+			sections = append(sections, section{typ, p, f.obj})
+		}
+		if strings.Contains(typ, "time.") {
+			imports["time"] = true
+		}
+		fmt.Fprintf(buf, "\t%s %s `mapstructure:%q`\n", GoName(f.key), typ, f.key)
+	}
+	buf.WriteString("}\n\n")
+	for _, s := range sections {
+		writeStruct(buf, s.name, s.path, s.obj, imports)
+	}
+}
+
+// writeValidate writes Config.Validate and the helpers its checks use.
+func writeValidate(buf *bytes.Buffer, root *object, sch *schema.Schema, imports map[string]bool) {
+	var checks []string
+	if sch != nil {
+		checks = rules(root, sch, nil, "c")
+	}
+	buf.WriteString("// Validate checks the values against the rules of the installed modules' config schemas\n")
+	buf.WriteString("// (config/schema.json). Unset values are accepted.\n")
+	buf.WriteString("func (c *Config) Validate() error {\n")
+	if len(checks) == 0 {
+		buf.WriteString("\treturn nil\n}\n")
+		return
+	}
+	imports["errors"], imports["fmt"] = true, true
+	buf.WriteString("\tvar errs []error\n")
+	helpers := map[string]bool{}
+	for _, c := range checks {
+		for _, h := range []string{"checkEnum", "checkPattern", "checkHostPort"} {
+			if strings.Contains(c, h+"(") {
+				helpers[h] = true
+			}
+		}
+		buf.WriteString(c)
+	}
+	buf.WriteString("\treturn errors.Join(errs...)\n}\n")
+
+	if helpers["checkEnum"] {
+		imports["slices"], imports["strings"] = true, true
+		buf.WriteString(`
+func checkEnum(key, v string, allowed ...string) error {
+	if v == "" || slices.Contains(allowed, v) {
+		return nil
+	}
+	return fmt.Errorf("%s: must be one of %s, got %q", key, strings.Join(allowed, ", "), v)
+}
+`)
+	}
+	if helpers["checkPattern"] {
+		imports["regexp"] = true
+		buf.WriteString(`
+func checkPattern(key, v, pattern string) error {
+	if v == "" || regexp.MustCompile(pattern).MatchString(v) {
+		return nil
+	}
+	return fmt.Errorf("%s: must match %s, got %q", key, pattern, v)
+}
+`)
+	}
+	if helpers["checkHostPort"] {
+		imports["net"] = true
+		buf.WriteString(`
+func checkHostPort(key, v string) error {
+	if v == "" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(v); err != nil {
+		return fmt.Errorf("%s: must be host:port, got %q", key, v)
+	}
+	return nil
+}
+`)
+	}
+}
+
+// rules returns the Go statements checking the fields of o that sch has rules for.
+func rules(o *object, sch *schema.Schema, path []string, expr string) []string {
+	var out []string
+	for _, f := range o.fields {
+		p := append(append([]string{}, path...), f.key)
+		s := sch.Lookup(p)
+		if s == nil {
+			continue
+		}
+		x := expr + "." + GoName(f.key)
+		if f.obj != nil {
+			out = append(out, rules(f.obj, sch, p, x)...)
+			continue
+		}
+		key := strconv.Quote(strings.Join(p, "."))
+		appendErr := func(call string) {
+			out = append(out, fmt.Sprintf("\tif err := %s; err != nil {\n\t\terrs = append(errs, err)\n\t}\n", call))
+		}
+		switch f.typ {
+		case "string":
+			if len(s.Enum) > 0 {
+				allowed := make([]string, len(s.Enum))
+				for i, e := range s.Enum {
+					allowed[i] = strconv.Quote(fmt.Sprint(e))
+				}
+				appendErr(fmt.Sprintf("checkEnum(%s, %s, %s)", key, x, strings.Join(allowed, ", ")))
+			}
+			if s.Pattern != "" {
+				appendErr(fmt.Sprintf("checkPattern(%s, %s, %s)", key, x, strconv.Quote(s.Pattern)))
+			}
+			if s.Format == "hostport" {
+				appendErr(fmt.Sprintf("checkHostPort(%s, %s)", key, x))
+			}
+		case "int", "float64":
+			bound := func(v *float64, op, word string, round func(float64) float64) {
+				if v == nil {
+					return
+				}
+				n := *v
+				if f.typ != "float64" {
+					n = round(n)
+				}
+				lit := strconv.FormatFloat(n, 'f', -1, 64)
+				out = append(out, fmt.Sprintf("\tif %s %s %s {\n\t\terrs = append(errs, fmt.Errorf(\"%%s: must be %s %s, got %%v\", %s, %s))\n\t}\n",
+					x, op, lit, word, lit, key, x))
+			}
+			bound(s.Minimum, "<", ">=", math.Ceil)
+			bound(s.Maximum, ">", "<=", math.Floor)
+		}
+	}
+	return out
+}
+
+// initialisms are written in upper case in Go names, as golint expects.
+var initialisms = map[string]bool{
+	"api": true, "db": true, "dns": true, "dsn": true, "grpc": true, "html": true, "http": true,
+	"https": true, "id": true, "ip": true, "json": true, "jwt": true, "rpc": true, "sql": true,
+	"tcp": true, "tls": true, "ttl": true, "udp": true, "uri": true, "url": true, "uuid": true,
+}
+
+// GoName returns the exported Go identifier for a config key: max_open_conns => MaxOpenConns,
+// http => HTTP.
+func GoName(key string) string {
+	parts := strings.FieldsFunc(key, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	var b strings.Builder
+	for _, p := range parts {
+		if initialisms[strings.ToLower(p)] {
+			b.WriteString(strings.ToUpper(p))
+			continue
+		}
+		r := []rune(p)
+		b.WriteString(strings.ToUpper(string(r[0])) + string(r[1:]))
+	}
+	name := b.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
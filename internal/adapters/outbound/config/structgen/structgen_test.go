@@ -0,0 +1,97 @@
+package structgen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/config/schema"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/config/structgen"
+)
+
+func TestGenerate(t *testing.T) {
+	base := `logger:
+  level: info
+server:
+  http:
+    addr: ":8080"
+gorm:
+  driver: sqlite
+  max_open_conns: 25
+  conn_max_lifetime: 30m
+  replicas: [a, b]
+  ratio: 1
+`
+	local := `gorm:
+  dsn: file:app.db
+  ratio: 0.5
+`
+	src, err := structgen.Generate("env", nil, []byte(base), []byte(local))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(src)
+	for _, want := range []string{
+		structgen.Header,
+		"package env",
+		"\t\"time\"\n",
+		"type Config struct {\n\tLogger LoggerConfig `mapstructure:\"logger\"`\n\tServer ServerConfig `mapstructure:\"server\"`\n\tGorm   GormConfig   `mapstructure:\"gorm\"`\n}",
+		"// ServerHTTPConfig is the server.http section.",
+		"HTTP ServerHTTPConfig `mapstructure:\"http\"`",
+		"Addr string `mapstructure:\"addr\"`",
+		"MaxOpenConns    int           `mapstructure:\"max_open_conns\"`",
+		"ConnMaxLifetime time.Duration `mapstructure:\"conn_max_lifetime\"`",
+		"Replicas        []string",
+		"Ratio           float64",
+		"DSN             string",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestGoName(t *testing.T) {
+	for key, want := range map[string]string{"max_open_conns": "MaxOpenConns", "grpc": "GRPC", "base-url": "BaseURL", "2fa": "X2fa"} {
+		if got := structgen.GoName(key); got != want {
+			t.Errorf("GoName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestGenerateValidate(t *testing.T) {
+	sch, err := schema.Parse([]byte(`{"properties": {
+  "gorm": {"properties": {
+    "driver": {"type": "string", "enum": ["postgres", "sqlite"]},
+    "max_open_conns": {"type": "integer", "minimum": 0, "maximum": 100.5}
+  }},
+  "server": {"properties": {"http": {"properties": {"addr": {"format": "hostport"}}}}}
+}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := structgen.Generate("env", sch, []byte("gorm:\n  driver: sqlite\n  max_open_conns: 5\nserver:\n  http:\n    addr: :80\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(src)
+	for _, want := range []string{
+		`if err := checkEnum("gorm.driver", c.Gorm.Driver, "postgres", "sqlite"); err != nil {`,
+		"if c.Gorm.MaxOpenConns < 0 {",
+		"if c.Gorm.MaxOpenConns > 100 {",
+		`if err := checkHostPort("server.http.addr", c.Server.HTTP.Addr); err != nil {`,
+		"return errors.Join(errs...)",
+		"\t\"net\"\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "func checkPattern") {
+		t.Error("unused helper generated")
+	}
+
+	src, _ = structgen.Generate("env", nil, []byte("a: 1\n"))
+	if !strings.Contains(string(src), "func (c *Config) Validate() error {\n\treturn nil\n}") {
+		t.Errorf("without schema:\n%s", src)
+	}
+}
@@ -0,0 +1,355 @@
+// Package yamledit edits YAML documents in place: keys are inserted and scalars replaced by
+// splicing text at the positions reported by yaml.Node, so the comments, key order, quoting and
+// blank lines of the rest of the document are kept byte for byte.
+package yamledit
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Merge adds the keys of defaults that are missing from src. Existing keys are never changed;
+// mappings present on both sides are merged recursively. Missing keys are appended at the end
+// of their mapping together with the comments written above them in defaults.
+// It reports whether src changed.
+func Merge(src, defaults []byte) ([]byte, bool, error) {
+	_, def, err := parse(defaults)
+	if err != nil {
+		return nil, false, fmt.Errorf("parse defaults: %w", err)
+	}
+	if def == nil || def.Kind != yaml.MappingNode || len(def.Content) == 0 {
+		return src, false, nil
+	}
+	doc, dst, err := parse(src)
+	if err != nil {
+		return nil, false, err
+	}
+	if dst == nil {
+		// Empty (or comment-only) document: keep what is there and append the defaults as written
+		return appendLines(src, strings.Split(strings.TrimRight(string(defaults), "\n"), "\n")), true, nil
+	}
+	if dst.Kind != yaml.MappingNode {
+		return nil, false, fmt.Errorf("top level is not a mapping")
+	}
+
+	var ins []insertion
+	collect(dst, def, 0, &ins)
+	if len(ins) == 0 {
+		return src, false, nil
+	}
+	for _, in := range ins {
+		if in.into.Style&yaml.FlowStyle != 0 || len(in.into.Content) == 0 {
+			out, err := reencode(doc, ins)
+			return out, err == nil, err
+		}
+	}
+	return splice(src, defaults, ins)
+}
+
+// Get returns the scalar at path, e.g. ["gorm", "driver"].
+func Get(src []byte, path []string) (string, bool) {
+	_, r, err := parse(src)
+	if err != nil || r == nil {
+		return "", false
+	}
+	n := walk(r, path)
+	if n == nil || n.Kind != yaml.ScalarNode {
+		return "", false
+	}
+	return n.Value, true
+}
+
+// Set sets the scalar at path to value. An existing scalar is replaced on its own line,
+// keeping its quoting style and trailing comment; a missing key is inserted like Merge does.
+func Set(src []byte, path []string, value any) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+	_, r, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	n := walk(r, path)
+	if n == nil {
+		// Build the nested mapping for path and merge it in
+		var v yaml.Node
+		if err := v.Encode(value); err != nil {
+			return nil, err
+		}
+		m := &v
+		for i := len(path) - 1; i >= 0; i-- {
+			m = &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{{Kind: yaml.ScalarNode, Value: path[i]}, m}}
+		}
+		def, err := encode(m, 0)
+		if err != nil {
+			return nil, err
+		}
+		out, _, err := Merge(src, []byte(strings.Join(def, "\n")+"\n"))
+		return out, err
+	}
+	if n.Kind != yaml.ScalarNode || n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return nil, fmt.Errorf("%s is not a single-line value", strings.Join(path, "."))
+	}
+	var v yaml.Node
+	if err := v.Encode(value); err != nil {
+		return nil, err
+	}
+	if v.Tag == "!!str" && n.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) != 0 {
+		v.Style = n.Style
+	}
+	text, err := encode(&v, 0)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(src), "\n")
+	line := lines[n.Line-1]
+	prefix, suffix := line[:n.Column-1], ""
+	if n.LineComment != "" {
+		if i := strings.LastIndex(line, n.LineComment); i >= n.Column-1 {
+			j := i
+			for j > n.Column-1 && (line[j-1] == ' ' || line[j-1] == '\t') {
+				j--
+			}
+			suffix = line[j:]
+		}
+	}
+	lines[n.Line-1] = prefix + text[0] + suffix
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// parse returns the document node and its top-level node, nil for an empty document.
+func parse(src []byte) (*yaml.Node, *yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return nil, nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return &doc, nil, nil
+	}
+	return &doc, doc.Content[0], nil
+}
+
+func lookup(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func walk(n *yaml.Node, path []string) *yaml.Node {
+	for _, p := range path {
+		if n = lookup(n, p); n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+// insertion is a set of key/value pairs missing from a mapping.
+type insertion struct {
+	into  *yaml.Node
+	pairs []*yaml.Node
+	depth int
+}
+
+func collect(dst, def *yaml.Node, depth int, out *[]insertion) {
+	var missing []*yaml.Node
+	for i := 0; i+1 < len(def.Content); i += 2 {
+		k, v := def.Content[i], def.Content[i+1]
+		existing := lookup(dst, k.Value)
+		switch {
+		case existing == nil:
+			missing = append(missing, k, v)
+		case existing.Kind == yaml.MappingNode && v.Kind == yaml.MappingNode:
+			collect(existing, v, depth+1, out)
+		}
+	}
+	if len(missing) > 0 {
+		*out = append(*out, insertion{into: dst, pairs: missing, depth: depth})
+	}
+}
+
+// splice inserts each block after the last line of its mapping, bottom-up so earlier
+// positions stay valid; nested blocks go before their parent's block on the same line.
+// Pairs are copied from the defaults text as written, re-indented, when possible.
+func splice(src, defaults []byte, ins []insertion) ([]byte, bool, error) {
+	lines := strings.Split(strings.TrimRight(string(src), "\n"), "\n")
+	defLines := strings.Split(strings.TrimRight(string(defaults), "\n"), "\n")
+	type block struct {
+		after int // 1-based line the block goes after
+		depth int
+		text  []string
+	}
+	var blocks []block
+	for _, in := range ins {
+		indent := in.into.Content[0].Column - 1
+		var text []string
+		for i := 0; i+1 < len(in.pairs); i += 2 {
+			pair, ok := rawPair(defLines, in.pairs[i], in.pairs[i+1], indent)
+			if !ok {
+				var err error
+				if pair, err = encode(&yaml.Node{Kind: yaml.MappingNode, Content: in.pairs[i : i+2]}, indent); err != nil {
+					return nil, false, err
+				}
+			}
+			// New top-level sections are separated by blank lines, like sections usually are
+			if in.depth == 0 {
+				text = append(text, "")
+			}
+			text = append(text, pair...)
+		}
+		end := endLine(lines, in.into, indent)
+		if in.depth == 0 && strings.TrimSpace(lines[end-1]) == "" {
+			text = text[1:]
+		}
+		blocks = append(blocks, block{after: end, depth: in.depth, text: text})
+	}
+	sort.SliceStable(blocks, func(i, j int) bool {
+		if blocks[i].after != blocks[j].after {
+			return blocks[i].after > blocks[j].after
+		}
+		return blocks[i].depth < blocks[j].depth
+	})
+	for _, b := range blocks {
+		tail := append(append([]string{}, b.text...), lines[b.after:]...)
+		lines = append(lines[:b.after], tail...)
+	}
+	return []byte(strings.Join(lines, "\n") + "\n"), true, nil
+}
+
+// rawPair returns the lines of key k (with the comments above it) and its value v as written
+// in the defaults, moved to indent. It fails for pairs whose lines cannot be told apart.
+func rawPair(lines []string, k, v *yaml.Node, indent int) ([]string, bool) {
+	from := k.Line - 1
+	if k.HeadComment != "" {
+		from -= strings.Count(k.HeadComment, "\n") + 1
+	}
+	if from < 0 || k.Line > len(lines) {
+		return nil, false
+	}
+	for _, l := range lines[from : k.Line-1] {
+		if !strings.HasPrefix(strings.TrimSpace(l), "#") {
+			return nil, false
+		}
+	}
+	keyIndent := k.Column - 1
+	end := maxLine(v)
+	if k.Line > end {
+		end = k.Line
+	}
+	for end < len(lines) && strings.TrimSpace(lines[end]) != "" && leading(lines[end]) > keyIndent {
+		end++
+	}
+	out := make([]string, 0, end-from)
+	for _, l := range lines[from:end] {
+		if strings.TrimSpace(l) == "" {
+			out = append(out, "")
+			continue
+		}
+		if leading(l) < keyIndent {
+			return nil, false
+		}
+		out = append(out, strings.Repeat(" ", indent)+l[keyIndent:])
+	}
+	return out, true
+}
+
+// endLine returns the last line of mapping m: its deepest node's line, extended over
+// continuation lines (block scalars, nested comments) indented deeper than m's keys.
+func endLine(lines []string, m *yaml.Node, indent int) int {
+	end := maxLine(m)
+	for end < len(lines) {
+		next := lines[end]
+		if strings.TrimSpace(next) == "" || leading(next) <= indent {
+			break
+		}
+		end++
+	}
+	return end
+}
+
+func maxLine(n *yaml.Node) int {
+	line := n.Line
+	for _, c := range n.Content {
+		if l := maxLine(c); l > line {
+			line = l
+		}
+	}
+	return line
+}
+
+func leading(s string) int { return len(s) - len(strings.TrimLeft(s, " ")) }
+
+// reencode is the fallback for flow-style or empty mappings, which cannot be spliced: the
+// pairs are added to the tree and the whole document is re-encoded. Comments and key order
+// are kept; blank lines and indentation may change.
+func reencode(doc *yaml.Node, ins []insertion) ([]byte, error) {
+	targets := map[*yaml.Node]bool{}
+	for _, in := range ins {
+		in.into.Content = append(in.into.Content, in.pairs...)
+		targets[in.into] = true
+	}
+	toBlock(doc, targets)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// toBlock switches every collection that contains a target to block style, since the
+// inserted keys may carry comments; it reports whether n contains a target.
+func toBlock(n *yaml.Node, targets map[*yaml.Node]bool) bool {
+	found := targets[n]
+	for _, c := range n.Content {
+		if toBlock(c, targets) {
+			found = true
+		}
+	}
+	if found {
+		n.Style &^= yaml.FlowStyle
+	}
+	return found
+}
+
+// encode renders n as block YAML indented by indent spaces, one string per line.
+func encode(n *yaml.Node, indent int) ([]string, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(n); err != nil {
+		return nil, err
+	}
+	pad := strings.Repeat(" ", indent)
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = pad + l
+		}
+	}
+	return lines, nil
+}
+
+func appendLines(src []byte, lines []string) []byte {
+	var buf bytes.Buffer
+	buf.Write(src)
+	if len(bytes.TrimSpace(src)) > 0 {
+		if !bytes.HasSuffix(src, []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+	}
+	buf.WriteString(strings.Join(lines, "\n"))
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
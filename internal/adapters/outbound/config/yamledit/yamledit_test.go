@@ -0,0 +1,112 @@
+package yamledit_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/config/yamledit"
+)
+
+const userConfig = `# app config
+
+# logging
+logger:
+  level: debug # debug|info
+
+server:
+  http:
+    addr: ":80"   # listen
+  banner: |
+    hello
+    world
+`
+
+const defaults = `server:
+  http:
+    addr: ":8080"
+    read_timeout: 5s
+  # gRPC server
+  grpc:
+    addr: ":9090"    # host:port
+
+# Database
+gorm:
+  driver: sqlite  # postgres | mysql | sqlite
+`
+
+func TestMergeKeepsDocumentAndInsertsMissingKeys(t *testing.T) {
+	out, changed, err := yamledit.Merge([]byte(userConfig), []byte(defaults))
+	if err != nil || !changed {
+		t.Fatalf("changed=%v err=%v", changed, err)
+	}
+	want := `# app config
+
+# logging
+logger:
+  level: debug # debug|info
+
+server:
+  http:
+    addr: ":80"   # listen
+    read_timeout: 5s
+  banner: |
+    hello
+    world
+  # gRPC server
+  grpc:
+    addr: ":9090"    # host:port
+
+# Database
+gorm:
+  driver: sqlite  # postgres | mysql | sqlite
+`
+	if string(out) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out, want)
+	}
+
+	again, changed, err := yamledit.Merge(out, []byte(defaults))
+	if err != nil || changed || string(again) != string(out) {
+		t.Errorf("second merge changed the file (changed=%v err=%v)", changed, err)
+	}
+}
+
+func TestMergeIntoEmptyAndFlowDocuments(t *testing.T) {
+	out, _, err := yamledit.Merge(nil, []byte(defaults))
+	if err != nil || string(out) != defaults {
+		t.Errorf("empty: err=%v\n%s", err, out)
+	}
+	out, _, err = yamledit.Merge([]byte("server: {http: {addr: x}}\n"), []byte(defaults))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "addr: x") || !strings.Contains(string(out), "# gRPC server") {
+		t.Errorf("flow:\n%s", out)
+	}
+}
+
+func TestSet(t *testing.T) {
+	out, err := yamledit.Set([]byte(defaults), []string{"gorm", "driver"}, "postgres")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "  driver: postgres  # postgres | mysql | sqlite\n") {
+		t.Errorf("replace:\n%s", out)
+	}
+	out, err = yamledit.Set(out, []string{"server", "http", "addr"}, ":7070")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := yamledit.Get(out, []string{"server", "http", "addr"}); v != ":7070" || !strings.Contains(string(out), `addr: ":7070"`) {
+		t.Errorf("quoted replace:\n%s", out)
+	}
+	out, err = yamledit.Set(out, []string{"gorm", "dsn"}, "file:app.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "  driver: postgres  # postgres | mysql | sqlite\n  dsn: file:app.db\n") {
+		t.Errorf("insert:\n%s", out)
+	}
+	if _, err := yamledit.Set(out, []string{"server"}, "x"); err == nil {
+		t.Error("expected an error replacing a mapping")
+	}
+}
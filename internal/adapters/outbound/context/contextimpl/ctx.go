@@ -7,10 +7,20 @@ type Ctx struct {
 	projectRoot    string
 	values         map[string]any
 	fs             ports.FSWriter
+	files          ports.FileSystem
 	renderer       ports.Renderer
+	templates      ports.TemplateRepo
 	gomod          ports.GoModEditor
 	adaptersModule ports.DependencyInjectionEditor
 	config         ports.ConfigEditor
+	tracker        ports.ModuleTracker
+	hooks          []ModuleHook
+}
+
+// ModuleHook is a post-generate hook together with the module that declared it.
+type ModuleHook struct {
+	Module string
+	Hook   ports.Hook
 }
 
 // New constructs a new Ctx.
@@ -30,6 +40,43 @@ func New(projectRoot string, fs ports.FSWriter, renderer ports.Renderer, gomod p
 	}
 }
 
+// WithTemplates sets the template repository modules load their templates from and returns c.
+func (c *Ctx) WithTemplates(repo ports.TemplateRepo) *Ctx {
+	c.templates = repo
+	return c
+}
+
+// WithFiles sets the file system project files are read and written through and returns c.
+func (c *Ctx) WithFiles(fsys ports.FileSystem) *Ctx {
+	c.files = fsys
+	return c
+}
+
+// WithTracker sets the tracker notified as modules are applied and returns c.
+func (c *Ctx) WithTracker(t ports.ModuleTracker) *Ctx {
+	c.tracker = t
+	return c
+}
+
+// Enter implements ports.ModuleTracker by forwarding to the configured tracker and to the
+// go.mod editor when it tracks modules too.
+func (c *Ctx) Enter(module string) {
+	if c.tracker != nil {
+		c.tracker.Enter(module)
+	}
+	if t, ok := c.gomod.(ports.ModuleTracker); ok {
+		t.Enter(module)
+	}
+}
+
+// Defer implements ports.HookCollector.
+func (c *Ctx) Defer(module string, h ports.Hook) {
+	c.hooks = append(c.hooks, ModuleHook{Module: module, Hook: h})
+}
+
+// Hooks returns the post-generate hooks deferred by applied modules, in application order.
+func (c *Ctx) Hooks() []ModuleHook { return c.hooks }
+
 // Values returns the context values map used in templates and path tokens.
 func (c *Ctx) Values() map[string]any { return c.values }
 
@@ -42,9 +89,15 @@ func (c *Ctx) ProjectRoot() string { return c.projectRoot }
 // FS returns the file system writer.
 func (c *Ctx) FS() ports.FSWriter { return c.fs }
 
+// Files returns the project file system.
+func (c *Ctx) Files() ports.FileSystem { return c.files }
+
 // Renderer returns the template renderer.
 func (c *Ctx) Renderer() ports.Renderer { return c.renderer }
 
+// Templates returns the template repository.
+func (c *Ctx) Templates() ports.TemplateRepo { return c.templates }
+
 // GoMod returns the go.mod editor utility.
 func (c *Ctx) GoMod() ports.GoModEditor { return c.gomod }
 
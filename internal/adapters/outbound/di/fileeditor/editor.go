@@ -7,142 +7,438 @@ import (
 	"go/parser"
 	"go/printer"
 	"go/token"
-	"os"
+	"go/types"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/osfs"
 	"github.com/nduyhai/gocraft/internal/core/ports"
+	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/imports"
 )
 
-// Editor implements ports.DependencyInjectionEditor by editing
-// <root>/internal/platform/di/root.go using AST and goimports.
-// All operations are idempotent and tolerate missing files.
+// Editor implements ports.DependencyInjectionEditor by editing the files of
+// <root>/internal/platform/di: root.go for fx, wire.go and providers.go for wire, app.go for
+// manual. Fx entries may target another file and function instead of root.go's Root.
+// Entries are inserted as text, one per line, before the closing parenthesis of the options
+// call, so the rest of the file keeps its layout; imports are then added through the AST and
+// goimports. All operations are idempotent.
 
-type Editor struct{ root string }
+type Editor struct {
+	fs   ports.FileSystem
+	root string
 
-func New(projectRoot string) *Editor { return &Editor{root: projectRoot} }
+	// regenerate is set once a Wire injector was edited, for Hooks.
+	regenerate bool
+}
+
+func New(projectRoot string) *Editor { return NewFS(osfs.New(), projectRoot) }
+
+// NewFS returns an editor that reads and writes through the given file system.
+func NewFS(fsys ports.FileSystem, projectRoot string) *Editor {
+	return &Editor{fs: fsys, root: projectRoot}
+}
+
+// diFile returns the path of a file of the DI package.
+func (e *Editor) diFile(name string) string {
+	return filepath.Join(e.root, "internal", "platform", "di", name)
+}
+
+// Mode detects the DI style from the root file present, fx when there is none yet.
+func (e *Editor) Mode() string {
+	for _, f := range []struct{ name, mode string }{
+		{"root.go", ports.DIFx},
+		{wireFile, ports.DIWire},
+		{appFile, ports.DIManual},
+	} {
+		if _, err := e.fs.Stat(e.diFile(f.name)); err == nil {
+			return f.mode
+		}
+	}
+	return ports.DIFx
+}
+
+func (e *Editor) EnsureModule(alias, importPath string) error {
+	if alias == "" || importPath == "" {
+		return fmt.Errorf("invalid module args: alias/importPath must be non-empty")
+	}
+	imps := []ports.DIImport{{Alias: alias, Path: importPath}}
+	switch e.Mode() {
+	case ports.DIWire:
+		return e.ensureWireModule(alias, imps)
+	case ports.DIManual:
+		return e.EnsureEntry(ports.DIEntry{Imports: imps, Expr: alias + ".Setup"})
+	}
+	return e.EnsureEntry(ports.DIEntry{Imports: imps, Expr: alias + ".Module()"})
+}
 
 func (e *Editor) Ensure(alias, importPath, optionExpr string) error {
 	if alias == "" || importPath == "" || optionExpr == "" {
 		return fmt.Errorf("invalid ensure args: alias/importPath/optionExpr must be non-empty")
 	}
-	return e.ensureInFile(
-		filepath.Join(e.root, "internal", "platform", "di", "root.go"),
-		alias, importPath, optionExpr,
-	)
+	return e.EnsureEntry(ports.DIEntry{
+		Imports: []ports.DIImport{{Alias: alias, Path: importPath}},
+		Expr:    optionExpr,
+	})
 }
 
-// ensureInFile ensures an import alias/path and fx option expression exist in the given file using AST.
-func (e *Editor) ensureInFile(filePath, alias, importPath, optionExpr string) error {
-	b, err := os.ReadFile(filePath)
-	if err != nil {
-		// Missing file: treat as no-op.
-		return nil
+// EnsureEntry adds the option, fx.Provide, fx.Invoke or fx.Decorate call described by entry
+// to the DI root, unless an equal one is there already; a constructor already listed in an
+// fx.Provide(a, b) call counts as provided.
+func (e *Editor) EnsureEntry(entry ports.DIEntry) error {
+	if entry.Expr == "" {
+		return fmt.Errorf("invalid DI entry: empty expression")
 	}
-
-	fset := token.NewFileSet()
-	f, err := parser.ParseFile(fset, filePath, b, parser.ParseComments)
-	if err != nil {
-		// If parsing fails, do not modify (avoid corruption); surface error to caller
-		return err
+	for _, imp := range entry.Imports {
+		if imp.Path == "" {
+			return fmt.Errorf("invalid DI entry %s: import without path", entry.Expr)
+		}
 	}
+	switch e.Mode() {
+	case ports.DIWire:
+		return e.ensureWire(entry)
+	case ports.DIManual:
+		return e.ensureManual(entry)
+	}
+	return e.ensureInFile(entry)
+}
 
-	// Ensure import exists with alias
-	ensureImport := func() {
-		for _, imp := range f.Imports {
-			pathVal := strings.Trim(imp.Path.Value, "\"")
-			name := ""
-			if imp.Name != nil {
-				name = imp.Name.Name
-			}
-			if pathVal == importPath && name == alias {
-				return // already present
+// ensureInFile ensures the entry and its imports exist in its file: the DI root, or its File,
+// created when missing along with its Func.
+func (e *Editor) ensureInFile(entry ports.DIEntry) error {
+	filePath, fn := e.diFile("root.go"), entry.Func
+	if entry.File != "" {
+		if !filepath.IsLocal(filepath.FromSlash(entry.File)) {
+			return fmt.Errorf("invalid DI entry %s: file %q escapes the project root", entry.Expr, entry.File)
+		}
+		filePath = filepath.Join(e.root, filepath.FromSlash(entry.File))
+		if fn == "" {
+			fn = "Module"
+		}
+		if err := e.ensureFile(filePath); err != nil {
+			return err
+		}
+	} else if fn == "" {
+		fn = "Root"
+	}
+	for _, name := range []string{fn, entry.Into} {
+		if name != "" && !token.IsIdentifier(name) {
+			return fmt.Errorf("invalid DI entry %s: %q is not a function name", entry.Expr, name)
+		}
+	}
+	_, err := e.edit(filePath, func(src []byte) ([]byte, error) {
+		out := src
+		var err error
+		if entry.File != "" {
+			if out, err = ensureFunc(filePath, out, fn); err != nil {
+				return nil, err
 			}
 		}
-		// Not present: add
-		imp := &ast.ImportSpec{
-			Name: &ast.Ident{Name: alias},
-			Path: &ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("\"%s\"", importPath)},
-		}
-		// Create or find import decl
-		var gen *ast.GenDecl
-		for _, d := range f.Decls {
-			if gd, ok := d.(*ast.GenDecl); ok && gd.Tok == token.IMPORT {
-				gen = gd
-				break
+		target := fn
+		if entry.Into != "" {
+			if out, err = ensureFunc(filePath, out, entry.Into); err != nil {
+				return nil, err
+			}
+			if out, err = ensureArg(filePath, out, fn, ports.DIEntry{Expr: entry.Into + "()"}); err != nil {
+				return nil, err
 			}
+			target = entry.Into
 		}
-		if gen == nil {
-			gen = &ast.GenDecl{Tok: token.IMPORT, Lparen: token.NoPos, Rparen: token.NoPos}
-			f.Decls = append([]ast.Decl{gen}, f.Decls...)
+		if out, err = ensureArg(filePath, out, target, entry); err != nil {
+			return nil, err
 		}
-		gen.Specs = append(gen.Specs, imp)
-		if gen.Lparen == token.NoPos && len(gen.Specs) > 1 {
-			// ensure it's a block import
-			gen.Lparen = token.Pos(1)
-			gen.Rparen = token.Pos(1)
+		return ensureImports(filePath, out, entry.Imports)
+	})
+	return err
+}
+
+// ensureFile creates filePath with just a package clause when it does not exist.
+func (e *Editor) ensureFile(filePath string) error {
+	if _, err := e.fs.Stat(filePath); err == nil {
+		return nil
+	}
+	if err := e.fs.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return err
+	}
+	return e.fs.WriteFile(filePath, []byte("package "+e.packageName(filepath.Dir(filePath))+"\n"), 0o644)
+}
+
+// packageName returns the package of the Go files already in dir (http/chi holds package
+// httpchi). Without any, it derives one from the directory: lower-cased letters, digits and
+// underscores of its last element.
+func (e *Editor) packageName(dir string) string {
+	entries, _ := e.fs.ReadDir(dir)
+	for _, de := range entries {
+		name := de.Name()
+		if de.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		src, err := e.fs.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		if f, err := parser.ParseFile(token.NewFileSet(), name, src, parser.PackageClauseOnly); err == nil {
+			return f.Name.Name
+		}
+	}
+	var b strings.Builder
+	for _, r := range strings.ToLower(filepath.Base(dir)) {
+		if r == '_' || ('a' <= r && r <= 'z') || ('0' <= r && r <= '9' && b.Len() > 0) {
+			b.WriteRune(r)
 		}
 	}
-	ensureImport()
+	if b.Len() == 0 {
+		return "main"
+	}
+	return b.String()
+}
 
-	// Ensure optionExpr exists inside return fx.Options(...)
-	exprToAdd, err := parser.ParseExpr(optionExpr)
+// edit rewrites filePath with change and tidies its imports; it reports whether the file
+// changed.
+func (e *Editor) edit(filePath string, change func(src []byte) ([]byte, error)) (bool, error) {
+	src, err := e.fs.ReadFile(filePath)
+	if err != nil {
+		return false, fmt.Errorf("di root: %w", err)
+	}
+	out, err := change(src)
 	if err != nil {
-		return fmt.Errorf("invalid optionExpr: %w", err)
+		return false, err
 	}
+	if bytes.Equal(out, src) {
+		return false, nil
+	}
+	if processed, err := imports.Process(filePath, out, &imports.Options{Comments: true, TabWidth: 8, Fragment: false}); err == nil {
+		out = processed
+	}
+	return true, e.fs.WriteFile(filePath, out, 0o644)
+}
 
-	foundAndEnsured := false
-	ast.Inspect(f, func(n ast.Node) bool {
-		ret, ok := n.(*ast.ReturnStmt)
-		if !ok {
-			return true
+// optionsCall returns the X.Options(...) call returned by function fn itself (returns of its
+// closures do not count), or nil.
+func optionsCall(f *ast.File, fn string) *ast.CallExpr {
+	for _, d := range f.Decls {
+		fd, ok := d.(*ast.FuncDecl)
+		if !ok || fd.Recv != nil || fd.Body == nil || fd.Name.Name != fn {
+			continue
 		}
-		for _, res := range ret.Results {
-			call, ok := res.(*ast.CallExpr)
-			if !ok {
-				continue
+		var found *ast.CallExpr
+		ast.Inspect(fd.Body, func(n ast.Node) bool {
+			if found != nil {
+				return false
+			}
+			if _, ok := n.(*ast.FuncLit); ok {
+				return false
 			}
-			sel, ok := call.Fun.(*ast.SelectorExpr)
-			if !ok || sel.Sel == nil || sel.Sel.Name != "Options" {
-				continue
+			ret, ok := n.(*ast.ReturnStmt)
+			if !ok {
+				return true
 			}
-			// naive check: qualifier exists (fx). We don't enforce package name here.
-			// ensure not duplicate
-			var buf bytes.Buffer
-			_ = printer.Fprint(&buf, fset, exprToAdd)
-			needle := buf.String()
-			already := false
-			for _, a := range call.Args {
-				buf.Reset()
-				_ = printer.Fprint(&buf, fset, a)
-				if buf.String() == needle {
-					already = true
-					break
+			for _, res := range ret.Results {
+				if call, ok := res.(*ast.CallExpr); ok && isSelector(call.Fun, "", "Options") {
+					found = call
+					return false
 				}
 			}
-			if !already {
-				call.Args = append(call.Args, exprToAdd)
+			return true
+		})
+		return found
+	}
+	return nil
+}
+
+// isSelector reports whether x is pkg.name (any pkg when pkg is empty).
+func isSelector(x ast.Expr, pkg, name string) bool {
+	sel, ok := x.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	id, ok := sel.X.(*ast.Ident)
+	return ok && (pkg == "" || id.Name == pkg)
+}
+
+// ensureArg adds the entry's expression to the options returned by fn.
+func ensureArg(filePath string, src []byte, fn string, entry ports.DIEntry) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filePath, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	call := optionsCall(f, fn)
+	if call == nil {
+		return nil, fmt.Errorf("%s: no return fx.Options(...) in %s() to add %s to", filePath, fn, entry.Expr)
+	}
+	fx := call.Fun.(*ast.SelectorExpr).X.(*ast.Ident).Name
+
+	text, inner, err := render(fx, entry)
+	if err != nil {
+		return nil, err
+	}
+	if hasArg(call, fx, entry.Kind, text, inner) {
+		return src, nil
+	}
+	return insertArg(fset, src, call, text), nil
+}
+
+// render returns the option expression for entry and, for the wrapping kinds, the wrapped one.
+func render(fx string, entry ports.DIEntry) (text, inner string, err error) {
+	x, err := parser.ParseExpr(entry.Expr)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid DI expression %q: %w", entry.Expr, err)
+	}
+	inner = types.ExprString(x)
+	var wrap string
+	switch entry.Kind {
+	case "", ports.DIOption:
+		if len(entry.As) > 0 || entry.Group != "" {
+			return "", "", fmt.Errorf("DI entry %s: As and Group need kind %s", entry.Expr, ports.DIProvide)
+		}
+		return inner, "", nil
+	case ports.DIProvide:
+		wrap = "Provide"
+	case ports.DIInvoke:
+		wrap = "Invoke"
+	case ports.DIDecorate:
+		wrap = "Decorate"
+	default:
+		return "", "", fmt.Errorf("DI entry %s: unknown kind %q", entry.Expr, entry.Kind)
+	}
+	if len(entry.As) > 0 || entry.Group != "" {
+		if entry.Kind != ports.DIProvide {
+			return "", "", fmt.Errorf("DI entry %s: As and Group need kind %s", entry.Expr, ports.DIProvide)
+		}
+		args := []string{inner}
+		for _, as := range entry.As {
+			args = append(args, fmt.Sprintf("%s.As(new(%s))", fx, as))
+		}
+		if entry.Group != "" {
+			args = append(args, fmt.Sprintf("%s.ResultTags(`group:%s`)", fx, strconv.Quote(entry.Group)))
+		}
+		a, err := parser.ParseExpr(fmt.Sprintf("%s.Annotate(%s)", fx, strings.Join(args, ", ")))
+		if err != nil {
+			return "", "", fmt.Errorf("invalid DI entry %s: %w", entry.Expr, err)
+		}
+		inner = types.ExprString(a)
+	}
+	return fmt.Sprintf("%s.%s(%s)", fx, wrap, inner), inner, nil
+}
+
+// hasArg reports whether the options call already holds text, or inner inside a call of the
+// same kind (fx.Provide(a, inner)).
+func hasArg(call *ast.CallExpr, fx string, kind ports.DIKind, text, inner string) bool {
+	name := map[ports.DIKind]string{ports.DIProvide: "Provide", ports.DIInvoke: "Invoke", ports.DIDecorate: "Decorate"}[kind]
+	for _, a := range call.Args {
+		if types.ExprString(a) == text {
+			return true
+		}
+		c, ok := a.(*ast.CallExpr)
+		if !ok || name == "" || !isSelector(c.Fun, fx, name) {
+			continue
+		}
+		for _, x := range c.Args {
+			if types.ExprString(x) == inner {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// insertArg adds text as the last argument of call: on its own line when the call spans
+// several lines (or has no arguments yet), after ", " otherwise.
+func insertArg(fset *token.FileSet, src []byte, call *ast.CallExpr, text string) []byte {
+	var last ast.Node
+	if len(call.Args) > 0 {
+		last = call.Args[len(call.Args)-1]
+	}
+	return insertItem(fset, src, call.Pos(), call.Rparen, last, text)
+}
+
+// insertItem adds text after last, the last item of a parenthesized list that closes at rparen
+// and belongs to the construct starting at start.
+func insertItem(fset *token.FileSet, src []byte, start, rparen token.Pos, last ast.Node, text string) []byte {
+	end := fset.Position(rparen).Offset
+	indent := lineIndent(src, fset.Position(start).Offset)
+	var ins string
+	at := end
+	switch {
+	case last == nil:
+		ins = "\n" + indent + "\t" + text + ",\n" + indent
+	case fset.Position(rparen).Line > fset.Position(last.End()).Line:
+		at = lineStart(src, end)
+		argIndent := lineIndent(src, fset.Position(last.Pos()).Offset)
+		ins = argIndent + text + ",\n"
+	default:
+		ins = ", " + text
+	}
+	out := make([]byte, 0, len(src)+len(ins))
+	out = append(out, src[:at]...)
+	out = append(out, ins...)
+	return append(out, src[at:]...)
+}
+
+func lineStart(src []byte, off int) int { return bytes.LastIndexByte(src[:off], '\n') + 1 }
+
+func lineIndent(src []byte, off int) string {
+	line := src[lineStart(src, off):]
+	return string(line[:len(line)-len(bytes.TrimLeft(line, " \t"))])
+}
+
+// ensureFunc appends `func name() fx.Option { return fx.Options() }` when the file lacks it,
+// importing go.uber.org/fx if needed.
+func ensureFunc(filePath string, src []byte, name string) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filePath, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if f.Scope.Lookup(name) != nil {
+		return src, nil
+	}
+	fx, imported := "fx", false
+	for _, imp := range f.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p == fxPath {
+			imported = true
+			if imp.Name != nil {
+				fx = imp.Name.Name
 			}
-			foundAndEnsured = true
-			return false
 		}
-		return true
-	})
-	// If not found, do nothing (idempotent across templates)
-	_ = foundAndEnsured
+	}
+	fn := fmt.Sprintf("\n// %s groups options registered by gocraft modules.\nfunc %s() %s.Option {\n\treturn %s.Options()\n}\n", name, name, fx, fx)
+	out := append(append(bytes.TrimRight(src, "\n"), '\n'), fn...)
+	if imported {
+		return out, nil
+	}
+	return ensureImports(filePath, out, []ports.DIImport{{Path: fxPath}})
+}
 
-	// Print and run goimports
-	var out bytes.Buffer
-	if err := printer.Fprint(&out, fset, f); err != nil {
-		return err
+// fxPath is the import path of Uber Fx.
+const fxPath = "go.uber.org/fx"
+
+// ensureImports adds the named imports the file lacks.
+func ensureImports(filePath string, src []byte, imps []ports.DIImport) ([]byte, error) {
+	if len(imps) == 0 {
+		return src, nil
 	}
-	processed, err := imports.Process(filePath, out.Bytes(), &imports.Options{Comments: true, TabWidth: 8, Fragment: false})
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filePath, src, parser.ParseComments)
 	if err != nil {
-		// Even if imports fails, write the printer output to avoid losing changes
-		processed = out.Bytes()
+		return nil, err
+	}
+	changed := false
+	for _, imp := range imps {
+		if astutil.AddNamedImport(fset, f, imp.Alias, imp.Path) {
+			changed = true
+		}
+	}
+	if !changed {
+		return src, nil
+	}
+	var out bytes.Buffer
+	if err := printer.Fprint(&out, fset, f); err != nil {
+		return nil, err
 	}
-	return os.WriteFile(filePath, processed, 0o644)
+	return out.Bytes(), nil
 }
 
 var _ ports.DependencyInjectionEditor = (*Editor)(nil)
@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	amedit "github.com/nduyhai/gocraft/internal/adapters/outbound/di/fileeditor"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/memfs"
+	"github.com/nduyhai/gocraft/internal/core/ports"
 )
 
 func write(t *testing.T, path, content string) {
@@ -68,3 +70,249 @@ func Root() fx.Option {
 		t.Fatalf("option expr occurrence count = %d, want 1. file=\n%s", c, got2)
 	}
 }
+
+func TestEditor_EnsureEntry(t *testing.T) {
+	fsys := memfs.New()
+	root := "app/internal/platform/di/root.go"
+	_ = fsys.MkdirAll("app/internal/platform/di", 0o755)
+	_ = fsys.WriteFile(root, []byte(`package di
+
+import (
+	"example.com/app/internal/platform/env"
+	"go.uber.org/fx"
+)
+
+// Root is the application's DI root.
+func Root() fx.Option {
+	return fx.Options(
+		env.Module(),
+		fx.Provide(newClock), // shared
+	)
+}
+`), 0o644)
+	ed := amedit.NewFS(fsys, "app")
+	repo := ports.DIImport{Alias: "gormrepo", Path: "example.com/app/internal/adapters/outbound/repo/gorm"}
+	entries := []ports.DIEntry{
+		{Kind: ports.DIProvide, Expr: "newClock"},
+		{Kind: ports.DIProvide, Imports: []ports.DIImport{repo, {Alias: "ports", Path: "example.com/app/internal/core/ports"}},
+			Expr: "gormrepo.NewUserRepo", As: []string{"ports.UserRepo"}},
+		{Kind: ports.DIProvide, Expr: "newHealthHandler", Group: "handlers", Into: "adapters"},
+		{Kind: ports.DIInvoke, Expr: "registerRoutes", Into: "adapters"},
+		{Kind: ports.DIDecorate, Expr: "withTracing"},
+	}
+	for i := 0; i < 2; i++ {
+		for _, e := range entries {
+			if err := ed.EnsureEntry(e); err != nil {
+				t.Fatalf("EnsureEntry(%s): %v", e.Expr, err)
+			}
+		}
+	}
+	b, _ := fsys.ReadFile(root)
+	want := `package di
+
+import (
+	gormrepo "example.com/app/internal/adapters/outbound/repo/gorm"
+	ports "example.com/app/internal/core/ports"
+	"example.com/app/internal/platform/env"
+	"go.uber.org/fx"
+)
+
+// Root is the application's DI root.
+func Root() fx.Option {
+	return fx.Options(
+		env.Module(),
+		fx.Provide(newClock), // shared
+		fx.Provide(fx.Annotate(gormrepo.NewUserRepo, fx.As(new(ports.UserRepo)))),
+		adapters(),
+		fx.Decorate(withTracing),
+	)
+}
+
+// adapters groups options registered by gocraft modules.
+func adapters() fx.Option {
+	return fx.Options(
+		fx.Provide(fx.Annotate(newHealthHandler, fx.ResultTags(` + "`" + `group:"handlers"` + "`" + `))),
+		fx.Invoke(registerRoutes),
+	)
+}
+`
+	if string(b) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b, want)
+	}
+
+	if err := ed.EnsureEntry(ports.DIEntry{Kind: ports.DIInvoke, Expr: "f", As: []string{"io.Reader"}}); err == nil {
+		t.Error("As accepted for fx.Invoke")
+	}
+	if err := amedit.NewFS(fsys, "missing").Ensure("x", "example.com/x", "x.Module()"); err == nil {
+		t.Error("missing DI root accepted")
+	}
+	_ = fsys.WriteFile(root, []byte("package di\n\nfunc Root() {}\n"), 0o644)
+	if err := ed.Ensure("x", "example.com/x", "x.Module()"); err == nil || !strings.Contains(err.Error(), "no return fx.Options") {
+		t.Errorf("missing options: %v", err)
+	}
+}
+
+func TestEditor_EnsureModuleWireAndManual(t *testing.T) {
+	fsys := memfs.New()
+	_ = fsys.MkdirAll("w/internal/platform/di", 0o755)
+	_ = fsys.WriteFile("w/internal/platform/di/wire.go", []byte(`//go:build wireinject
+
+package di
+
+import (
+	"example.com/app/internal/platform/app"
+	"github.com/google/wire"
+)
+
+func Initialize() (*app.App, error) {
+	wire.Build(
+		app.ProviderSet,
+		newApp,
+	)
+	return nil, nil
+}
+`), 0o644)
+	_ = fsys.WriteFile("w/internal/platform/di/providers.go", []byte(`package di
+
+import (
+	"example.com/app/internal/platform/app"
+)
+
+func newApp(
+	a *app.App,
+) *app.App {
+	return a
+}
+`), 0o644)
+	_ = fsys.MkdirAll("m/internal/platform/di", 0o755)
+	_ = fsys.WriteFile("m/internal/platform/di/app.go", []byte(`package di
+
+import (
+	"example.com/app/internal/platform/app"
+)
+
+func Build() (*app.App, error) {
+	a, err := app.New()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.Setup(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+`), 0o644)
+
+	wired, manual := amedit.NewFS(fsys, "w"), amedit.NewFS(fsys, "m")
+	if wired.Mode() != ports.DIWire || manual.Mode() != ports.DIManual {
+		t.Fatalf("modes = %s, %s", wired.Mode(), manual.Mode())
+	}
+	for i := 0; i < 2; i++ {
+		for _, ed := range []*amedit.Editor{wired, manual} {
+			if err := ed.EnsureModule("httpchi", "example.com/app/internal/adapters/inbound/http/chi"); err != nil {
+				t.Fatalf("EnsureModule: %v", err)
+			}
+		}
+	}
+	for path, want := range map[string]string{
+		"w/internal/platform/di/wire.go":      "\t\tnewApp,\n\t\thttpchi.ProviderSet,\n\t)",
+		"w/internal/platform/di/providers.go": "\ta *app.App,\n\t_ httpchi.Component,\n)",
+		"m/internal/platform/di/app.go":       "\tif err := a.Setup(\n\t\thttpchi.Setup,\n\t); err != nil {",
+	} {
+		b, _ := fsys.ReadFile(path)
+		if !strings.Contains(string(b), want) || !strings.Contains(string(b), `httpchi "example.com/app/internal/adapters/inbound/http/chi"`) {
+			t.Errorf("%s:\n%s\nwant it to contain:\n%s", path, b, want)
+		}
+	}
+	if len(wired.Hooks(nil)) == 0 || len(manual.Hooks(nil)) != 0 {
+		t.Error("wire_gen.go regeneration should follow Wire edits only")
+	}
+
+	if err := wired.EnsureEntry(ports.DIEntry{Kind: ports.DIInvoke, Expr: "registerRoutes"}); err == nil {
+		t.Error("fx.Invoke accepted with di=wire")
+	}
+	if err := manual.EnsureEntry(ports.DIEntry{Kind: ports.DIProvide, Expr: "newClock"}); err == nil {
+		t.Error("provide accepted with di=manual")
+	}
+}
+
+func TestEditor_EnsureEntryFileAndFunc(t *testing.T) {
+	fsys := memfs.New()
+	root := "app/internal/platform/di/root.go"
+	_ = fsys.MkdirAll("app/internal/platform/di", 0o755)
+	_ = fsys.WriteFile(root, []byte(`package di
+
+import "go.uber.org/fx"
+
+func helpers() fx.Option {
+	return fx.Options()
+}
+
+func Root() fx.Option {
+	return fx.Options(
+		helpers(),
+	)
+}
+`), 0o644)
+	ed := amedit.NewFS(fsys, "app")
+	inbound := ports.DIImport{Alias: "inbound", Path: "example.com/app/internal/adapters/inbound"}
+	entries := []ports.DIEntry{
+		{Imports: []ports.DIImport{inbound}, Expr: "inbound.Module()"},
+		{Kind: ports.DIProvide, Expr: "newRouter", File: "internal/adapters/inbound/module.go"},
+		{Kind: ports.DIInvoke, Expr: "registerRoutes", File: "internal/adapters/inbound/module.go", Func: "Routes"},
+	}
+	for i := 0; i < 2; i++ {
+		for _, e := range entries {
+			if err := ed.EnsureEntry(e); err != nil {
+				t.Fatalf("EnsureEntry(%s): %v", e.Expr, err)
+			}
+		}
+	}
+	b, _ := fsys.ReadFile(root)
+	if !strings.Contains(string(b), "return fx.Options(\n\t\thelpers(),\n\t\tinbound.Module(),\n\t)") ||
+		!strings.Contains(string(b), "func helpers() fx.Option {\n\treturn fx.Options()\n}") {
+		t.Errorf("root.go:\n%s", b)
+	}
+	b, _ = fsys.ReadFile("app/internal/adapters/inbound/module.go")
+	want := `package inbound
+
+import "go.uber.org/fx"
+
+// Module groups options registered by gocraft modules.
+func Module() fx.Option {
+	return fx.Options(
+		fx.Provide(newRouter),
+	)
+}
+
+// Routes groups options registered by gocraft modules.
+func Routes() fx.Option {
+	return fx.Options(
+		fx.Invoke(registerRoutes),
+	)
+}
+`
+	if string(b) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b, want)
+	}
+
+	if err := ed.EnsureEntry(ports.DIEntry{Expr: "x.Module()", Func: "Missing"}); err == nil || !strings.Contains(err.Error(), "Missing()") {
+		t.Errorf("missing function of the DI root: %v", err)
+	}
+
+	// A new file joins the package already in its directory
+	_ = fsys.MkdirAll("app/internal/adapters/inbound/http/chi", 0o755)
+	_ = fsys.WriteFile("app/internal/adapters/inbound/http/chi/module.go", []byte("package httpchi\n"), 0o644)
+	if err := ed.EnsureEntry(ports.DIEntry{Kind: ports.DIProvide, Expr: "newExtra", File: "internal/adapters/inbound/http/chi/extra.go"}); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := fsys.ReadFile("app/internal/adapters/inbound/http/chi/extra.go"); !strings.HasPrefix(string(b), "package httpchi\n") {
+		t.Errorf("extra.go:\n%s", b)
+	}
+
+	for _, file := range []string{"../../x.go", "/tmp/x.go"} {
+		if err := ed.EnsureEntry(ports.DIEntry{Expr: "x.Module()", File: file}); err == nil {
+			t.Errorf("File %s outside the project accepted", file)
+		}
+	}
+}
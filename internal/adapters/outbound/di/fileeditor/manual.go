@@ -0,0 +1,23 @@
+package fileeditor
+
+import "github.com/nduyhai/gocraft/internal/core/ports"
+
+// Manual projects build the app by hand in app.go, whose Build function passes each module's
+// Setup function to a.Setup.
+const appFile = "app.go"
+
+// ensureManual adds the setup function of entry to a.Setup in Build.
+func (e *Editor) ensureManual(entry ports.DIEntry) error {
+	if err := plainEntry(entry, ports.DIManual, ports.DIOption); err != nil {
+		return err
+	}
+	path := e.diFile(appFile)
+	_, err := e.edit(path, func(src []byte) ([]byte, error) {
+		out, err := ensureCallArg(path, src, "Build", "Setup", entry.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return ensureImports(path, out, entry.Imports)
+	})
+	return err
+}
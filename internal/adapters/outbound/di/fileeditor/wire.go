@@ -0,0 +1,172 @@
+package fileeditor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/nduyhai/gocraft/internal/core/ports"
+)
+
+// Wire projects list provider sets in the wire.Build call of wire.go (built with the
+// wireinject tag only) and build each module's Component through the parameters of newApp in
+// providers.go: Wire rejects sets nothing uses.
+const (
+	wireFile      = "wire.go"
+	providersFile = "providers.go"
+
+	// wireCmd regenerates wire_gen.go; its version matches the wire require of generated projects.
+	wireCmd = "github.com/google/wire/cmd/wire@v0.6.0"
+)
+
+// ensureWire adds the provider (set) expression of entry to wire.Build.
+func (e *Editor) ensureWire(entry ports.DIEntry) error {
+	if err := plainEntry(entry, ports.DIWire, ports.DIOption, ports.DIProvide); err != nil {
+		return err
+	}
+	path := e.diFile(wireFile)
+	changed, err := e.edit(path, func(src []byte) ([]byte, error) {
+		out, err := ensureCallArg(path, src, "", "Build", entry.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return ensureImports(path, out, entry.Imports)
+	})
+	e.regenerate = e.regenerate || changed
+	return err
+}
+
+// ensureWireModule adds alias.ProviderSet to wire.Build and an alias.Component parameter to
+// newApp.
+func (e *Editor) ensureWireModule(alias string, imps []ports.DIImport) error {
+	if err := e.ensureWire(ports.DIEntry{Imports: imps, Expr: alias + ".ProviderSet"}); err != nil {
+		return err
+	}
+	path := e.diFile(providersFile)
+	changed, err := e.edit(path, func(src []byte) ([]byte, error) {
+		out, err := ensureParam(path, src, "newApp", alias+".Component")
+		if err != nil {
+			return nil, err
+		}
+		return ensureImports(path, out, imps)
+	})
+	e.regenerate = e.regenerate || changed
+	return err
+}
+
+// Hooks implements ports.HookProvider: once an edited Wire injector is on disk, wire_gen.go is
+// regenerated. Wire loads the project's packages, so go.sum is completed first.
+func (e *Editor) Hooks(ports.Ctx) []ports.Hook {
+	if !e.regenerate {
+		return nil
+	}
+	return []ports.Hook{
+		wireHook("go", "mod", "tidy"),
+		wireHook("go", "run", wireCmd, "gen", "./internal/platform/di"),
+	}
+}
+
+// wireHook runs one step of the wire_gen.go regeneration. The project does not build until it
+// succeeds, so a failure spells out the commands that finish the job by hand.
+func wireHook(args ...string) ports.Hook {
+	return ports.Hook{
+		Name:  strings.Join(args, " "),
+		Stage: ports.HookPostGenerate,
+		Run: func(dir string) (string, error) {
+			cmd := exec.Command(args[0], args[1:]...)
+			cmd.Dir = dir
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				err = fmt.Errorf("%w; wire_gen.go is out of date, regenerate it with: cd %s && go mod tidy && go run %s gen ./internal/platform/di", err, dir, wireCmd)
+			}
+			return strings.TrimSpace(string(out)), err
+		},
+	}
+}
+
+// plainEntry rejects entries of other kinds than kinds, and the options only fx can express.
+func plainEntry(entry ports.DIEntry, mode string, kinds ...ports.DIKind) error {
+	if len(entry.As) > 0 || entry.Group != "" || entry.Into != "" || entry.File != "" || entry.Func != "" {
+		return fmt.Errorf("DI entry %s: As, Group, Into, File and Func need di=%s", entry.Expr, ports.DIFx)
+	}
+	kind := entry.Kind
+	if kind == "" {
+		kind = ports.DIOption
+	}
+	if !slices.Contains(kinds, kind) {
+		return fmt.Errorf("DI entry %s: kind %s is not supported with di=%s", entry.Expr, kind, mode)
+	}
+	return nil
+}
+
+// ensureCallArg adds expr to the first X.name(...) call in function fn (any function when
+// empty).
+func ensureCallArg(filePath string, src []byte, fn, name, expr string) ([]byte, error) {
+	x, err := parser.ParseExpr(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DI expression %q: %w", expr, err)
+	}
+	text := types.ExprString(x)
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filePath, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var call *ast.CallExpr
+	for _, d := range f.Decls {
+		fd, ok := d.(*ast.FuncDecl)
+		if !ok || fd.Body == nil || (fn != "" && fd.Name.Name != fn) {
+			continue
+		}
+		ast.Inspect(fd.Body, func(n ast.Node) bool {
+			if c, ok := n.(*ast.CallExpr); ok && call == nil && isSelector(c.Fun, "", name) {
+				call = c
+			}
+			return call == nil
+		})
+		if call != nil {
+			break
+		}
+	}
+	if call == nil {
+		return nil, fmt.Errorf("%s: no %s(...) call to add %s to", filePath, name, text)
+	}
+	for _, a := range call.Args {
+		if types.ExprString(a) == text {
+			return src, nil
+		}
+	}
+	return insertArg(fset, src, call, text), nil
+}
+
+// ensureParam adds an unnamed parameter of type typ to function fn, unless it has one.
+func ensureParam(filePath string, src []byte, fn, typ string) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filePath, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var fd *ast.FuncDecl
+	for _, d := range f.Decls {
+		if d, ok := d.(*ast.FuncDecl); ok && d.Recv == nil && d.Name.Name == fn {
+			fd = d
+		}
+	}
+	if fd == nil {
+		return nil, fmt.Errorf("%s: no func %s to add a %s parameter to", filePath, fn, typ)
+	}
+	params := fd.Type.Params
+	var last ast.Node
+	for _, p := range params.List {
+		if types.ExprString(p.Type) == typ {
+			return src, nil
+		}
+		last = p
+	}
+	return insertItem(fset, src, fd.Pos(), params.Closing, last, "_ "+typ), nil
+}
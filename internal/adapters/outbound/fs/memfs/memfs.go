@@ -0,0 +1,303 @@
+package memfs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nduyhai/gocraft/internal/core/ports"
+)
+
+// Op describes how a staged path differs from the base file system.
+type Op string
+
+const (
+	OpCreate Op = "create"
+	OpModify Op = "modify"
+	OpDelete Op = "delete"
+)
+
+// Change is a single staged modification.
+type Change struct {
+	Path string
+	Op   Op
+}
+
+// FS is an in-memory ports.FileSystem. When created with NewOverlay it reads through
+// to a base file system for paths it does not hold, without ever writing to it, so a
+// whole generation can be staged, inspected (dry run, diff) and then committed or dropped.
+type FS struct {
+	mu      sync.RWMutex
+	base    ports.FileSystem
+	files   map[string]*file
+	dirs    map[string]bool
+	removed map[string]bool
+}
+
+type file struct {
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+	existed bool // present in base when first staged
+}
+
+// New returns an empty in-memory file system.
+func New() *FS { return NewOverlay(nil) }
+
+// NewOverlay returns an in-memory file system layered over base (which may be nil).
+func NewOverlay(base ports.FileSystem) *FS {
+	return &FS{
+		base:    base,
+		files:   make(map[string]*file),
+		dirs:    make(map[string]bool),
+		removed: make(map[string]bool),
+	}
+}
+
+func key(name string) string { return filepath.Clean(name) }
+
+func (m *FS) ReadFile(name string) ([]byte, error) {
+	k := key(name)
+	m.mu.RLock()
+	f, ok := m.files[k]
+	removed := m.removed[k]
+	m.mu.RUnlock()
+	if ok {
+		return append([]byte(nil), f.data...), nil
+	}
+	if removed || m.base == nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return m.base.ReadFile(name)
+}
+
+func (m *FS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	k := key(name)
+	if fi, err := m.Stat(filepath.Dir(k)); err != nil || !fi.IsDir() {
+		return &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, ok := m.files[k]
+	if !ok {
+		// Only paths present in base are ever marked removed, so a removed path rewritten
+		// here still modifies the original
+		f = &file{existed: m.removed[k] || m.inBase(k)}
+		m.files[k] = f
+	}
+	delete(m.removed, k)
+	f.data = append([]byte(nil), data...)
+	f.mode = perm.Perm()
+	f.modTime = time.Now()
+	return nil
+}
+
+func (m *FS) MkdirAll(path string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for p := key(path); ; p = filepath.Dir(p) {
+		if _, ok := m.files[p]; ok {
+			return &fs.PathError{Op: "mkdir", Path: p, Err: errors.New("not a directory")}
+		}
+		m.dirs[p] = true
+		if parent := filepath.Dir(p); parent == p {
+			return nil
+		}
+	}
+}
+
+func (m *FS) Stat(name string) (fs.FileInfo, error) {
+	k := key(name)
+	m.mu.RLock()
+	f, isFile := m.files[k]
+	isDir := m.dirs[k] || m.hasChildren(k)
+	removed := m.removed[k]
+	m.mu.RUnlock()
+	switch {
+	case isFile:
+		return fileInfo{name: filepath.Base(k), size: int64(len(f.data)), mode: f.mode, modTime: f.modTime}, nil
+	case isDir:
+		return fileInfo{name: filepath.Base(k), mode: fs.ModeDir | 0o755}, nil
+	case !removed && m.base != nil:
+		return m.base.Stat(name)
+	// The root of a pure in-memory FS always exists.
+	case k == "." || k == string(filepath.Separator):
+		return fileInfo{name: k, mode: fs.ModeDir | 0o755}, nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+func (m *FS) Remove(name string) error {
+	k := key(name)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, ok := m.files[k]
+	if ok {
+		delete(m.files, k)
+		if f.existed {
+			m.removed[k] = true
+		}
+		return nil
+	}
+	if !m.removed[k] && m.inBase(k) {
+		m.removed[k] = true
+		return nil
+	}
+	return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir lists the directory name, with staged files and directories merged over the base
+// and removed files left out, sorted by name.
+func (m *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	k := key(name)
+	fi, err := m.Stat(k)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	byName := make(map[string]fs.DirEntry)
+	if m.base != nil {
+		if entries, err := m.base.ReadDir(name); err == nil {
+			for _, e := range entries {
+				byName[e.Name()] = e
+			}
+		}
+	}
+	m.mu.RLock()
+	for p := range m.removed {
+		if filepath.Dir(p) == k {
+			delete(byName, filepath.Base(p))
+		}
+	}
+	dir := func(name string) fs.DirEntry {
+		return fs.FileInfoToDirEntry(fileInfo{name: name, mode: fs.ModeDir | 0o755})
+	}
+	for p, f := range m.files {
+		if child, nested := childOf(k, p); child != "" && nested {
+			byName[child] = dir(child)
+		} else if child != "" {
+			byName[child] = fs.FileInfoToDirEntry(fileInfo{name: child, size: int64(len(f.data)), mode: f.mode, modTime: f.modTime})
+		}
+	}
+	for p := range m.dirs {
+		if child, _ := childOf(k, p); child != "" {
+			byName[child] = dir(child)
+		}
+	}
+	m.mu.RUnlock()
+	out := make([]fs.DirEntry, 0, len(byName))
+	for _, e := range byName {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out, nil
+}
+
+// childOf returns the first element of p below dir, and whether p lies deeper than that.
+func childOf(dir, p string) (string, bool) {
+	rel, err := filepath.Rel(dir, p)
+	if err != nil || rel == "." || !filepath.IsLocal(rel) {
+		return "", false
+	}
+	child, rest, _ := strings.Cut(rel, string(filepath.Separator))
+	return child, rest != ""
+}
+
+// Files returns the paths of all files held in memory, sorted.
+func (m *FS) Files() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]string, 0, len(m.files))
+	for k := range m.files {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Changes returns the staged modifications relative to the base file system, sorted by path.
+func (m *FS) Changes() []Change {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Change, 0, len(m.files)+len(m.removed))
+	for k, f := range m.files {
+		op := OpCreate
+		if f.existed {
+			op = OpModify
+		}
+		out = append(out, Change{Path: k, Op: op})
+	}
+	for k := range m.removed {
+		out = append(out, Change{Path: k, Op: OpDelete})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// Commit applies every staged change to dst (usually the base file system).
+func (m *FS) Commit(dst ports.FileSystem) error {
+	for _, c := range m.Changes() {
+		if c.Op == OpDelete {
+			if err := dst.Remove(c.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("remove %s: %w", c.Path, err)
+			}
+			continue
+		}
+		m.mu.RLock()
+		f := m.files[c.Path]
+		m.mu.RUnlock()
+		if err := dst.MkdirAll(filepath.Dir(c.Path), 0o755); err != nil {
+			return fmt.Errorf("mkdir %s: %w", filepath.Dir(c.Path), err)
+		}
+		if err := dst.WriteFile(c.Path, f.data, f.mode); err != nil {
+			return fmt.Errorf("write %s: %w", c.Path, err)
+		}
+	}
+	return nil
+}
+
+// hasChildren reports whether any in-memory file lives under dir. Callers hold m.mu.
+func (m *FS) hasChildren(dir string) bool {
+	prefix := dir + string(filepath.Separator)
+	if dir == string(filepath.Separator) {
+		prefix = dir
+	}
+	for k := range m.files {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// inBase reports whether name exists in the base file system. Callers hold m.mu.
+func (m *FS) inBase(name string) bool {
+	if m.base == nil {
+		return false
+	}
+	_, err := m.base.Stat(name)
+	return err == nil
+}
+
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (fi fileInfo) Name() string       { return fi.name }
+func (fi fileInfo) Size() int64        { return fi.size }
+func (fi fileInfo) Mode() fs.FileMode  { return fi.mode }
+func (fi fileInfo) ModTime() time.Time { return fi.modTime }
+func (fi fileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi fileInfo) Sys() any           { return nil }
+
+var _ ports.FileSystem = (*FS)(nil)
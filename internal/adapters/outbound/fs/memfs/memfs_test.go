@@ -0,0 +1,142 @@
+package memfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/memfs"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/osfs"
+	gomodfileeditor "github.com/nduyhai/gocraft/internal/adapters/outbound/gomod/fileeditor"
+)
+
+func TestFS_ReadWriteStat(t *testing.T) {
+	m := memfs.New()
+	if err := m.WriteFile("app/go.mod", []byte("x"), 0o644); err == nil {
+		t.Fatal("expected error writing into a missing directory")
+	}
+	if err := m.MkdirAll("app/cmd", 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := m.WriteFile("app/cmd/main.go", []byte("package main"), 0o755); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	b, err := m.ReadFile("app/cmd/main.go")
+	if err != nil || string(b) != "package main" {
+		t.Fatalf("ReadFile = %q, %v", b, err)
+	}
+	fi, err := m.Stat("app/cmd/main.go")
+	if err != nil || fi.Mode().Perm() != 0o755 || fi.IsDir() {
+		t.Fatalf("Stat file = %v, %v", fi, err)
+	}
+	if fi, err := m.Stat("app"); err != nil || !fi.IsDir() {
+		t.Fatalf("Stat dir = %v, %v", fi, err)
+	}
+	if _, err := m.Stat("other"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Stat missing err = %v", err)
+	}
+}
+
+func TestFS_OverlayStagesAndCommits(t *testing.T) {
+	dir := t.TempDir()
+	gomod := filepath.Join(dir, "go.mod")
+	if err := os.WriteFile(gomod, []byte("module example.com/app\n\ngo 1.22\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stale := filepath.Join(dir, "stale.txt")
+	if err := os.WriteFile(stale, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stage := memfs.NewOverlay(osfs.New())
+	// Editors work unchanged on top of the staged file system.
+	if err := gomodfileeditor.NewFS(stage, dir).Add("github.com/spf13/cobra", "v1.8.1"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := stage.WriteFile(filepath.Join(dir, "new.txt"), []byte("new"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := stage.Remove(stale); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+
+	// Nothing reached the disk yet.
+	if b, _ := os.ReadFile(gomod); string(b) != "module example.com/app\n\ngo 1.22\n" {
+		t.Fatalf("go.mod modified before commit: %s", b)
+	}
+	if _, err := os.Stat(stale); err != nil {
+		t.Fatalf("stale removed before commit: %v", err)
+	}
+
+	want := []memfs.Change{
+		{Path: gomod, Op: memfs.OpModify},
+		{Path: filepath.Join(dir, "new.txt"), Op: memfs.OpCreate},
+		{Path: stale, Op: memfs.OpDelete},
+	}
+	got := stage.Changes()
+	if len(got) != len(want) {
+		t.Fatalf("Changes = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Changes[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	if err := stage.Commit(osfs.New()); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "new.txt")); string(b) != "new" {
+		t.Fatalf("new.txt = %q", b)
+	}
+	if _, err := os.Stat(stale); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("stale still present: %v", err)
+	}
+}
+
+func TestFS_ReadDirMergesOverlay(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"kept.go", "gone.go"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("package x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stage := memfs.NewOverlay(osfs.New())
+	_ = stage.Remove(filepath.Join(dir, "gone.go"))
+	_ = stage.WriteFile(filepath.Join(dir, "new.go"), []byte("package x"), 0o644)
+	_ = stage.MkdirAll(filepath.Join(dir, "sub", "deep"), 0o755)
+
+	entries, err := stage.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	var got []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() {
+			name += "/"
+		}
+		got = append(got, name)
+	}
+	if want := "kept.go new.go sub/"; strings.Join(got, " ") != want {
+		t.Fatalf("entries = %v, want %s", got, want)
+	}
+}
+
+func TestFS_RemoveThenRewriteIsModify(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(name, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stage := memfs.NewOverlay(osfs.New())
+	_ = stage.WriteFile(name, []byte("staged"), 0o644)
+	_ = stage.Remove(name)
+	_ = stage.WriteFile(name, []byte("new"), 0o644)
+	if got := stage.Changes(); len(got) != 1 || got[0].Op != memfs.OpModify {
+		t.Fatalf("Changes = %v, want a single modify", got)
+	}
+}
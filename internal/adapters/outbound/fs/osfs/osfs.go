@@ -0,0 +1,29 @@
+package osfs
+
+import (
+	"io/fs"
+	"os"
+
+	"github.com/nduyhai/gocraft/internal/core/ports"
+)
+
+// FS implements ports.FileSystem on top of the os package.
+type FS struct{}
+
+func New() FS { return FS{} }
+
+func (FS) ReadFile(name string) ([]byte, error) { return os.ReadFile(name) }
+
+func (FS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+
+func (FS) MkdirAll(path string, perm fs.FileMode) error { return os.MkdirAll(path, perm) }
+
+func (FS) Stat(name string) (fs.FileInfo, error) { return os.Stat(name) }
+
+func (FS) Remove(name string) error { return os.Remove(name) }
+
+func (FS) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+
+var _ ports.FileSystem = FS{}
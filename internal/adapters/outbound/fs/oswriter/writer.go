@@ -4,35 +4,77 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
-	"os"
 	"path/filepath"
 
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/osfs"
 	"github.com/nduyhai/gocraft/internal/core/entity"
+	"github.com/nduyhai/gocraft/internal/core/ports"
 )
 
-type Writer struct{}
+// Conflict is the strategy used when a generated file already exists.
+type Conflict string
 
-func New() *Writer { return &Writer{} }
+const (
+	ConflictError     Conflict = "error"     // fail the generation (default)
+	ConflictSkip      Conflict = "skip"      // keep the existing file
+	ConflictOverwrite Conflict = "overwrite" // replace it with the generated one
+)
+
+// ParseConflict validates a strategy name; the empty string selects ConflictError.
+func ParseConflict(s string) (Conflict, error) {
+	switch c := Conflict(s); c {
+	case "":
+		return ConflictError, nil
+	case ConflictError, ConflictSkip, ConflictOverwrite:
+		return c, nil
+	}
+	return "", fmt.Errorf("unknown conflict strategy %q (use error, skip or overwrite)", s)
+}
+
+// Writer implements ports.FSWriter on top of a ports.FileSystem (the OS by default).
+type Writer struct {
+	fs       ports.FileSystem
+	conflict Conflict
+}
 
-func (Writer) WriteAll(root string, files []entity.File) error {
+func New() *Writer { return NewFS(osfs.New()) }
+
+// NewFS returns a writer that writes through the given file system.
+func NewFS(fsys ports.FileSystem) *Writer { return &Writer{fs: fsys, conflict: ConflictError} }
+
+// WithConflict sets the strategy for files that already exist and returns w.
+func (w *Writer) WithConflict(c Conflict) *Writer {
+	w.conflict = c
+	return w
+}
+
+func (w Writer) WriteAll(root string, files []entity.File) error {
 	for _, f := range files {
 		path := filepath.Join(root, f.Path)
 		dir := filepath.Dir(path)
-		if err := os.MkdirAll(dir, 0o755); err != nil {
+		if err := w.fs.MkdirAll(dir, 0o755); err != nil {
 			return fmt.Errorf("mkdir %s: %w", dir, err)
 		}
-		if err := writeFile(path, f.Content, f.Mode); err != nil {
+		if err := w.writeFile(path, f.Content, f.Mode); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func writeFile(path string, content []byte, mode fs.FileMode) error {
-	if _, err := os.Stat(path); err == nil {
+func (w Writer) writeFile(path string, content []byte, mode fs.FileMode) error {
+	if _, err := w.fs.Stat(path); err == nil {
+		switch w.conflict {
+		case ConflictSkip:
+			return nil
+		case ConflictOverwrite:
+			return w.fs.WriteFile(path, content, mode)
+		}
 		return fmt.Errorf("file exists: %s", path)
-	} else if !errors.Is(err, os.ErrNotExist) {
+	} else if !errors.Is(err, fs.ErrNotExist) {
 		return fmt.Errorf("stat %s: %w", path, err)
 	}
-	return os.WriteFile(path, content, mode)
+	return w.fs.WriteFile(path, content, mode)
 }
+
+var _ ports.FSWriter = (*Writer)(nil)
@@ -0,0 +1,61 @@
+package tracked
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sync"
+
+	"github.com/nduyhai/gocraft/internal/core/ports"
+)
+
+// FS wraps a ports.FileSystem and records which module wrote each file. It implements
+// ports.ModuleTracker so the registry can tell it which module is being applied.
+type FS struct {
+	ports.FileSystem
+
+	mu      sync.Mutex
+	current string
+	origins map[string][]string
+}
+
+func New(base ports.FileSystem) *FS {
+	return &FS{FileSystem: base, origins: make(map[string][]string)}
+}
+
+// Enter implements ports.ModuleTracker.
+func (t *FS) Enter(module string) {
+	t.mu.Lock()
+	t.current = module
+	t.mu.Unlock()
+}
+
+func (t *FS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if err := t.FileSystem.WriteFile(name, data, perm); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current == "" {
+		return nil
+	}
+	k := filepath.Clean(name)
+	for _, m := range t.origins[k] {
+		if m == t.current {
+			return nil
+		}
+	}
+	t.origins[k] = append(t.origins[k], t.current)
+	return nil
+}
+
+// Origins returns the modules that wrote name, in the order they first wrote it.
+func (t *FS) Origins(name string) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.origins[filepath.Clean(name)]...)
+}
+
+var (
+	_ ports.FileSystem    = (*FS)(nil)
+	_ ports.ModuleTracker = (*FS)(nil)
+)
@@ -3,30 +3,56 @@ package fileeditor
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/osfs"
+	"github.com/nduyhai/gocraft/internal/core/ports"
 	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
 )
 
 // Editor provides a go.mod editor backed by x/mod/modfile for safe edits.
-// It supports adding require entries and replace directives in an idempotent way.
-// Tidy remains a no-op (callers run go tooling separately).
+// It supports adding require entries and replace directives in an idempotent way; the
+// version written by Add follows the editor's Policy (PolicyMinimum by default).
+// Tidy runs `go mod tidy` in the project root, so it only sees changes already on disk.
 
 type Editor struct {
-	root string
+	fs     ports.FileSystem
+	root   string
+	policy Policy
+
+	module   string
+	bumps    []Bump
+	warnings []string
+	majors   map[string]map[string]string // module prefix -> major -> requested by
 }
 
-func New(projectRoot string) *Editor { return &Editor{root: projectRoot} }
+func New(projectRoot string) *Editor { return NewFS(osfs.New(), projectRoot) }
+
+// NewFS returns an editor that reads and writes go.mod through the given file system.
+func NewFS(fsys ports.FileSystem, projectRoot string) *Editor {
+	return &Editor{fs: fsys, root: projectRoot, policy: PolicyMinimum}
+}
+
+// WithPolicy sets the version policy used by Add and returns e.
+func (e *Editor) WithPolicy(p Policy) *Editor {
+	e.policy = p
+	return e
+}
 
 func (e *Editor) goModPath() string { return filepath.Join(e.root, "go.mod") }
 
-// Add ensures the require entry exists with the specified version. If it already exists, it is left untouched.
+// Add ensures a require entry for module exists. The version written depends on the policy:
+// pinned uses version as-is, minimum keeps the higher of the existing and requested versions,
+// latest-cached prefers the newest version available offline. Changes are recorded as Bumps.
 func (e *Editor) Add(module, version string) error {
 	if module == "" {
 		return fmt.Errorf("module path is empty")
 	}
 	path := e.goModPath()
-	data, err := os.ReadFile(path)
+	data, err := e.fs.ReadFile(path)
 	if err != nil {
 		// Missing go.mod: no-op for resilience
 		return nil
@@ -35,18 +61,37 @@ func (e *Editor) Add(module, version string) error {
 	if err != nil {
 		return err
 	}
+	by := e.module
+	if by == "" {
+		by = "gocraft"
+	}
+	for _, r := range mf.Require {
+		e.checkMajor(r.Mod.Path, r.Mod.Version, "go.mod")
+	}
+	want := e.resolve(module, version, by)
+	e.checkMajor(module, want, by)
+
+	var existing string
 	for _, r := range mf.Require {
 		if r.Mod.Path == module {
-			// already has a require: keep as-is to avoid breaking constraints
-			return nil
+			existing = r.Mod.Version
+			break
 		}
 	}
-	if err := mf.AddRequire(module, version); err != nil {
+	switch {
+	case existing == want:
+		return nil
+	case existing != "" && e.policy != PolicyPinned && semver.Compare(existing, want) >= 0:
+		// already satisfies the request
+		return nil
+	}
+	if err := mf.AddRequire(module, want); err != nil {
 		return err
 	}
+	e.bumps = append(e.bumps, Bump{Path: module, From: existing, To: want, Module: e.module})
 	mf.Cleanup()
 	formatted := modfile.Format(mf.Syntax)
-	return os.WriteFile(path, formatted, 0o644)
+	return e.fs.WriteFile(path, formatted, 0o644)
 }
 
 // Replace adds or updates a replace directive. Versions are left empty for path-based replaces.
@@ -55,7 +100,7 @@ func (e *Editor) Replace(oldPath, newPath string) error {
 		return fmt.Errorf("replace paths must be non-empty")
 	}
 	path := e.goModPath()
-	data, err := os.ReadFile(path)
+	data, err := e.fs.ReadFile(path)
 	if err != nil {
 		return nil
 	}
@@ -78,8 +123,48 @@ func (e *Editor) Replace(oldPath, newPath string) error {
 	}
 	mf.Cleanup()
 	formatted := modfile.Format(mf.Syntax)
-	return os.WriteFile(path, formatted, 0o644)
+	return e.fs.WriteFile(path, formatted, 0o644)
+}
+
+// Tidy runs `go mod tidy` in the project root. It first tries offline (GOPROXY=off), so
+// projects whose dependencies are already in the module cache need no network, and falls
+// back to the configured proxy when something is missing.
+func (e *Editor) Tidy() error {
+	out, err := goModTidy(e.root, "GOPROXY=off")
+	if err == nil {
+		return nil
+	}
+	if !missingModules(out) {
+		return fmt.Errorf("go mod tidy: %w\n%s", err, out)
+	}
+	if out, err = goModTidy(e.root); err != nil {
+		if missingModules(out) || strings.Contains(out, "dial tcp") {
+			return fmt.Errorf("go mod tidy: required modules are not in the module cache and could not be downloaded; "+
+				"run `go mod download` in %s when online\n%s", e.root, out)
+		}
+		return fmt.Errorf("go mod tidy: %w\n%s", err, out)
+	}
+	return nil
+}
+
+// goModTidy runs `go mod tidy` in dir with -mod=mod and the extra environment.
+func goModTidy(dir string, env ...string) (string, error) {
+	cmd := exec.Command("go", "mod", "tidy")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS="+strings.TrimSpace(os.Getenv("GOFLAGS")+" -mod=mod"))
+	cmd.Env = append(cmd.Env, env...)
+	out, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
+
+// missingModules reports whether go failed because a module could not be fetched.
+func missingModules(out string) bool {
+	for _, s := range []string{"GOPROXY=off", "cannot find module", "no matching versions", "unrecognized import path"} {
+		if strings.Contains(out, s) {
+			return true
+		}
+	}
+	return false
 }
 
-// Tidy is a no-op placeholder.
-func (e *Editor) Tidy() error { return nil }
+var _ ports.GoModEditor = (*Editor)(nil)
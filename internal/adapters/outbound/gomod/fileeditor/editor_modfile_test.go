@@ -68,3 +68,55 @@ require (
 		t.Fatalf("duplicate replace count=%d", c)
 	}
 }
+
+func TestGoModEditor_VersionPolicies(t *testing.T) {
+	cache := t.TempDir()
+	t.Setenv("GOMODCACHE", cache)
+	t.Setenv("GOPROXY", "off")
+	for _, v := range []string{"v1.9.0", "v1.10.0", "v1.11.0-rc.1"} {
+		writeFile(t, filepath.Join(cache, "cache", "download", "github.com", "acme", "kit", "@v", v+".zip"), "")
+	}
+	const base = "module example.com/app\n\ngo 1.22\n\nrequire github.com/acme/kit v1.8.0\n"
+
+	cases := []struct {
+		policy  gmedit.Policy
+		request string
+		want    string
+	}{
+		{gmedit.PolicyMinimum, "v1.7.0", "v1.8.0"},
+		{gmedit.PolicyMinimum, "v1.9.1", "v1.9.1"},
+		{gmedit.PolicyPinned, "v1.7.0", "v1.7.0"},
+		{gmedit.PolicyLatestCached, "v1.7.0", "v1.10.0"},
+		{gmedit.PolicyLatestCached, "v1.12.0", "v1.12.0"},
+	}
+	for _, c := range cases {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "go.mod"), base)
+		ed := gmedit.New(dir).WithPolicy(c.policy)
+		if err := ed.Add("github.com/acme/kit", c.request); err != nil {
+			t.Fatalf("%s: %v", c.policy, err)
+		}
+		b, _ := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if !strings.Contains(string(b), "github.com/acme/kit "+c.want+"\n") {
+			t.Errorf("%s %s: go.mod = %s, want %s", c.policy, c.request, b, c.want)
+		}
+		if bumped := len(ed.Bumps()) == 1; bumped != (c.want != "v1.8.0") {
+			t.Errorf("%s %s: bumps = %+v", c.policy, c.request, ed.Bumps())
+		}
+	}
+}
+
+func TestGoModEditor_WarnsOnIncompatibleMajors(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n\ngo 1.22\n")
+	ed := gmedit.New(dir)
+	ed.Enter("http:chi")
+	_ = ed.Add("github.com/go-chi/chi/v5", "v5.0.12")
+	ed.Enter("source:legacy")
+	_ = ed.Add("github.com/go-chi/chi", "v1.5.5")
+
+	w := ed.Warnings()
+	if len(w) != 1 || !strings.Contains(w[0], "v5 by http:chi") || !strings.Contains(w[0], "v1 by source:legacy") {
+		t.Fatalf("warnings = %q", w)
+	}
+}
@@ -0,0 +1,194 @@
+package fileeditor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// Policy decides which version Add writes for a require.
+type Policy string
+
+const (
+	// PolicyPinned writes exactly the version the module asks for, even over an existing require.
+	PolicyPinned Policy = "pinned"
+	// PolicyLatestCached writes the newest version available offline, from GOMODCACHE or a
+	// file:// GOPROXY mirror, falling back to the module's version when it is not cached.
+	PolicyLatestCached Policy = "latest-cached"
+	// PolicyMinimum keeps the higher of the existing and requested versions, like MVS.
+	PolicyMinimum Policy = "minimum"
+)
+
+// Policies lists the supported policies; the first is the default.
+var Policies = []Policy{PolicyMinimum, PolicyPinned, PolicyLatestCached}
+
+// ParsePolicy validates a policy name; the empty string selects PolicyMinimum.
+func ParsePolicy(s string) (Policy, error) {
+	if s == "" {
+		return PolicyMinimum, nil
+	}
+	for _, p := range Policies {
+		if string(p) == s {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unknown version policy %q (use minimum, pinned or latest-cached)", s)
+}
+
+// Bump records a require whose version changed because of a module.
+type Bump struct {
+	Path   string
+	From   string // empty when the require was added
+	To     string
+	Module string // module being applied, if known
+}
+
+// resolve returns the version to require for path under the editor's policy.
+func (e *Editor) resolve(path, version, by string) string {
+	if e.policy != PolicyLatestCached {
+		return version
+	}
+	latest := latestCached(path)
+	switch {
+	case latest == "":
+		e.warnf("%s: no cached version, using %s", path, version)
+		return version
+	case semver.Compare(latest, version) < 0:
+		e.warnf("%s: %s needs %s but the newest cached version is %s", path, by, version, latest)
+		return version
+	}
+	return latest
+}
+
+// checkMajor warns when path is requested at a major version other than one already seen
+// for the same module (e.g. chi and chi/v5, or v1 and v2.0.0+incompatible).
+func (e *Editor) checkMajor(path, version, by string) {
+	prefix, pathMajor, ok := module.SplitPathVersion(path)
+	if !ok {
+		return
+	}
+	major := strings.TrimPrefix(pathMajor, "/")
+	if major == "" || strings.HasPrefix(major, ".") {
+		major = semver.Major(version)
+		if major == "v0" {
+			major = "v1" // v0 and v1 share an import path
+		}
+	}
+	if e.majors == nil {
+		e.majors = make(map[string]map[string]string)
+	}
+	seen := e.majors[prefix]
+	if seen == nil {
+		seen = make(map[string]string)
+		e.majors[prefix] = seen
+	}
+	if _, ok := seen[major]; !ok {
+		for other, owner := range seen {
+			e.warnf("%s: incompatible major versions requested: %s by %s and %s by %s", prefix, other, owner, major, by)
+		}
+		seen[major] = by
+	}
+}
+
+func (e *Editor) warnf(format string, args ...any) {
+	e.warnings = append(e.warnings, fmt.Sprintf(format, args...))
+}
+
+// Bumps returns the requires added or changed so far, in order.
+func (e *Editor) Bumps() []Bump { return append([]Bump(nil), e.bumps...) }
+
+// Warnings returns version warnings collected so far, in order.
+func (e *Editor) Warnings() []string { return append([]string(nil), e.warnings...) }
+
+// Enter implements ports.ModuleTracker so bumps and warnings name the module responsible.
+func (e *Editor) Enter(module string) { e.module = module }
+
+// latestCached returns the newest release of path available offline, or "" if none is.
+// Pre-releases and pseudo-versions are only used when there is no release.
+func latestCached(path string) string {
+	esc, err := module.EscapePath(path)
+	if err != nil {
+		return ""
+	}
+	var versions []string
+	for _, dir := range cacheDirs() {
+		versions = append(versions, listVersions(filepath.Join(dir, filepath.FromSlash(esc), "@v"))...)
+	}
+	var releases, others []string
+	for _, v := range versions {
+		if !semver.IsValid(v) {
+			continue
+		}
+		if semver.Prerelease(v) == "" && semver.Build(v) == "" {
+			releases = append(releases, v)
+		} else {
+			others = append(others, v)
+		}
+	}
+	if len(releases) == 0 {
+		releases = others
+	}
+	if len(releases) == 0 {
+		return ""
+	}
+	semver.Sort(releases)
+	return releases[len(releases)-1]
+}
+
+// cacheDirs returns the download cache and any file:// GOPROXY mirrors, both laid out
+// as <dir>/<escaped path>/@v/.
+func cacheDirs() []string {
+	var dirs []string
+	cache := os.Getenv("GOMODCACHE")
+	if cache == "" {
+		gopath := os.Getenv("GOPATH")
+		if gopath == "" {
+			if home, err := os.UserHomeDir(); err == nil {
+				gopath = filepath.Join(home, "go")
+			}
+		}
+		if gopath != "" {
+			cache = filepath.Join(filepath.SplitList(gopath)[0], "pkg", "mod")
+		}
+	}
+	if cache != "" {
+		dirs = append(dirs, filepath.Join(cache, "cache", "download"))
+	}
+	for _, p := range strings.FieldsFunc(os.Getenv("GOPROXY"), func(r rune) bool { return r == ',' || r == '|' }) {
+		if strings.HasPrefix(p, "file://") {
+			dirs = append(dirs, filepath.FromSlash(strings.TrimPrefix(p, "file://")))
+		}
+	}
+	return dirs
+}
+
+// listVersions returns the versions in an @v directory: those listed in its "list" file
+// (proxy mirrors) plus those whose zip is present (the module cache).
+func listVersions(dir string) []string {
+	set := map[string]bool{}
+	if b, err := os.ReadFile(filepath.Join(dir, "list")); err == nil {
+		for _, line := range strings.Split(string(b), "\n") {
+			if f := strings.Fields(line); len(f) > 0 {
+				set[f[0]] = true
+			}
+		}
+	}
+	if entries, err := os.ReadDir(dir); err == nil {
+		for _, e := range entries {
+			if v, ok := strings.CutSuffix(e.Name(), ".zip"); ok {
+				set[v] = true
+			}
+		}
+	}
+	out := make([]string, 0, len(set))
+	for v := range set {
+		out = append(out, v)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,137 @@
+package fileeditor
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nduyhai/gocraft/internal/core/ports"
+	"golang.org/x/mod/modfile"
+)
+
+// FileName is the workspace file looked up by Find.
+const FileName = "go.work"
+
+// ErrNotFound is returned by Find when no go.work governs the directory.
+var ErrNotFound = errors.New("no go.work found")
+
+// Find returns the go.work governing dir, like the go command: $GOWORK when set
+// ("off" disables workspaces), otherwise the first go.work in dir or one of its parents.
+func Find(fsys ports.FileSystem, dir string) (string, error) {
+	switch gowork := os.Getenv("GOWORK"); gowork {
+	case "":
+	case "off":
+		return "", fmt.Errorf("%w: GOWORK=off", ErrNotFound)
+	default:
+		return gowork, nil
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for d := abs; ; d = filepath.Dir(d) {
+		p := filepath.Join(d, FileName)
+		if fi, err := fsys.Stat(p); err == nil && !fi.IsDir() {
+			return p, nil
+		}
+		if filepath.Dir(d) == d {
+			return "", fmt.Errorf("%w in %s or any parent directory", ErrNotFound, abs)
+		}
+	}
+}
+
+// Editor edits a go.work file through a ports.FileSystem, using x/mod/modfile.
+type Editor struct {
+	fs   ports.FileSystem
+	path string
+}
+
+// NewFS returns an editor for the go.work file at path.
+func NewFS(fsys ports.FileSystem, path string) *Editor { return &Editor{fs: fsys, path: path} }
+
+// Dir returns the workspace root, the directory holding go.work.
+func (e *Editor) Dir() string { return filepath.Dir(e.path) }
+
+// Rel returns dir relative to the workspace root in go.work form ("./services/billing").
+// It fails when dir lies outside the workspace.
+func (e *Editor) Rel(dir string) (string, error) {
+	root, err := filepath.Abs(e.Dir())
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the workspace %s", dir, root)
+	}
+	if rel == "." {
+		return ".", nil
+	}
+	return "./" + filepath.ToSlash(rel), nil
+}
+
+// Uses returns the module directories listed in use directives.
+func (e *Editor) Uses() ([]string, error) {
+	wf, err := e.parse()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(wf.Use))
+	for _, u := range wf.Use {
+		out = append(out, u.Path)
+	}
+	return out, nil
+}
+
+// GoVersion returns the workspace's go directive, or "" when it has none.
+func (e *Editor) GoVersion() (string, error) {
+	wf, err := e.parse()
+	if err != nil || wf.Go == nil {
+		return "", err
+	}
+	return wf.Go.Version, nil
+}
+
+// Use adds a use directive for dir (a module directory); it is a no-op when already present.
+func (e *Editor) Use(dir string) error {
+	rel, err := e.Rel(dir)
+	if err != nil {
+		return err
+	}
+	wf, err := e.parse()
+	if err != nil {
+		return err
+	}
+	for _, u := range wf.Use {
+		if filepath.Clean(u.Path) == filepath.Clean(rel) {
+			return nil
+		}
+	}
+	if err := wf.AddUse(rel, ""); err != nil {
+		return err
+	}
+	wf.SortBlocks()
+	wf.Cleanup()
+	return e.fs.WriteFile(e.path, modfile.Format(wf.Syntax), 0o644)
+}
+
+func (e *Editor) parse() (*modfile.WorkFile, error) {
+	data, err := e.fs.ReadFile(e.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w at %s", ErrNotFound, e.path)
+		}
+		return nil, err
+	}
+	wf, err := modfile.ParseWork(e.path, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", e.path, err)
+	}
+	return wf, nil
+}
@@ -0,0 +1,52 @@
+package fileeditor_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/fs/osfs"
+	gwedit "github.com/nduyhai/gocraft/internal/adapters/outbound/gowork/fileeditor"
+)
+
+func TestGoWorkEditor_FindAndUse(t *testing.T) {
+	t.Setenv("GOWORK", "")
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.work"), []byte("go 1.24.0\n\nuse ./pkg\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	svc := filepath.Join(root, "services", "billing")
+	if err := os.MkdirAll(svc, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := gwedit.Find(osfs.New(), svc)
+	if err != nil || path != filepath.Join(root, "go.work") {
+		t.Fatalf("Find = %s, %v", path, err)
+	}
+	ed := gwedit.NewFS(osfs.New(), path)
+	for i := 0; i < 2; i++ {
+		if err := ed.Use(svc); err != nil {
+			t.Fatalf("Use: %v", err)
+		}
+	}
+	uses, err := ed.Uses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(uses, ",") != "./pkg,./services/billing" {
+		t.Fatalf("uses = %v", uses)
+	}
+	if err := ed.Use(t.TempDir()); err == nil {
+		t.Fatal("expected error for a module outside the workspace")
+	}
+}
+
+func TestGoWorkEditor_FindMissing(t *testing.T) {
+	t.Setenv("GOWORK", "off")
+	if _, err := gwedit.Find(osfs.New(), t.TempDir()); !errors.Is(err, gwedit.ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+}
@@ -1,25 +1,153 @@
 package exec
 
 import (
+	"bytes"
+	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/nduyhai/gocraft/internal/core/ports"
 )
 
-type Hook struct{}
+// Step is a single named post-generation step run inside the target directory.
+type Step struct {
+	Name string
+	Run  func(dir string) (output string, err error)
+}
+
+// Hook runs its steps in order as a ports.PostHook.
+type Hook struct {
+	steps []Step
+}
+
+func New(steps ...Step) *Hook { return &Hook{steps: steps} }
+
+// Steps returns the configured steps, e.g. to list them on a dry run.
+func (h *Hook) Steps() []Step { return h.steps }
+
+// Run executes the steps in targetDir, stopping at the first failure.
+func (h *Hook) Run(targetDir string) ([]ports.StepResult, error) {
+	results := make([]ports.StepResult, 0, len(h.steps))
+	var failed error
+	for _, s := range h.steps {
+		if failed != nil {
+			results = append(results, ports.StepResult{Name: s.Name, Status: ports.StepSkipped})
+			continue
+		}
+		out, err := s.Run(targetDir)
+		res := ports.StepResult{Name: s.Name, Status: ports.StepOK, Output: out}
+		if err != nil {
+			res.Status, res.Err = ports.StepFailed, err
+			failed = fmt.Errorf("%s: %w", s.Name, err)
+		}
+		results = append(results, res)
+	}
+	return results, failed
+}
+
+// Command returns a step running an external command; its name is the command line.
+func Command(name string, args ...string) Step {
+	words := []string{name}
+	for _, a := range args {
+		if strings.ContainsAny(a, " \t\"'") {
+			a = strconv.Quote(a)
+		}
+		words = append(words, a)
+	}
+	return Step{
+		Name: strings.Join(words, " "),
+		Run: func(dir string) (string, error) {
+			return run(dir, name, args...)
+		},
+	}
+}
+
+// FromHook returns a step running a module's post-generate hook: its Go callback if set,
+// otherwise its command, in the hook's Dir below the target directory with Env added.
+func FromHook(module string, h ports.Hook) Step {
+	name := h.Name
+	if name == "" && len(h.Command) > 0 {
+		name = Command(h.Command[0], h.Command[1:]...).Name
+	}
+	return Step{
+		Name: module + ": " + name,
+		Run: func(dir string) (string, error) {
+			if h.Dir != "" {
+				sub := filepath.Clean(filepath.FromSlash(h.Dir))
+				if filepath.IsAbs(sub) || sub == ".." || strings.HasPrefix(sub, ".."+string(filepath.Separator)) {
+					return "", fmt.Errorf("hook dir %q escapes the project root", h.Dir)
+				}
+				dir = filepath.Join(dir, sub)
+			}
+			if h.Run != nil {
+				return h.Run(dir)
+			}
+			if len(h.Command) == 0 {
+				return "", fmt.Errorf("hook declares neither a command nor a callback")
+			}
+			return runEnv(dir, h.Env, h.Command[0], h.Command[1:]...)
+		},
+	}
+}
+
+// GitInit returns the steps that initialize a repository and commit everything in it.
+func GitInit(message string) []Step {
+	return []Step{
+		Command("git", "init"),
+		Command("git", "add", "-A"),
+		Command("git", "commit", "-m", message),
+	}
+}
 
-func New() *Hook { return &Hook{} }
+// GitCommit returns the steps that commit paths (relative to the target directory) in an
+// existing repository. Other changes in the work tree and the index are left alone; paths that
+// neither exist nor are tracked are skipped.
+func GitCommit(message string, paths ...string) []Step {
+	var commit []string
+	return []Step{
+		{
+			Name: "git add -A -- " + strings.Join(paths, " "),
+			Run: func(dir string) (string, error) {
+				commit = commit[:0]
+				for _, p := range paths {
+					if _, err := os.Lstat(filepath.Join(dir, p)); err == nil {
+						commit = append(commit, p)
+					} else if _, err := run(dir, "git", "ls-files", "--error-unmatch", "--", p); err == nil {
+						commit = append(commit, p)
+					}
+				}
+				if len(commit) == 0 {
+					return "", fmt.Errorf("nothing to commit")
+				}
+				return run(dir, "git", append([]string{"add", "-A", "--"}, commit...)...)
+			},
+		},
+		{
+			Name: Command("git", "commit", "-m", message).Name,
+			Run: func(dir string) (string, error) {
+				return run(dir, "git", append([]string{"commit", "-m", message, "--"}, commit...)...)
+			},
+		},
+	}
+}
 
-// Run executes a few best-effort commands: git init and go mod tidy.
-func (Hook) Run(targetDir string) error {
-	// ignore errors intentionally (best effort)
-	_ = run(targetDir, "git", "init")
-	_ = run(targetDir, "git", "add", ".")
-	_ = run(targetDir, "git", "commit", "-m", "chore: initial commit")
-	_ = run(targetDir, "go", "mod", "tidy")
-	return nil
+func run(dir, name string, args ...string) (string, error) {
+	return runEnv(dir, nil, name, args...)
 }
 
-func run(dir, name string, args ...string) error {
+func runEnv(dir string, env []string, name string, args ...string) (string, error) {
 	cmd := exec.Command(name, args...)
 	cmd.Dir = dir
-	return cmd.Run()
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	var out bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &out
+	err := cmd.Run()
+	return strings.TrimSpace(out.String()), err
 }
+
+var _ ports.PostHook = (*Hook)(nil)
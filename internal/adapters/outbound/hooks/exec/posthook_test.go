@@ -0,0 +1,109 @@
+package exec_test
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	hookexec "github.com/nduyhai/gocraft/internal/adapters/outbound/hooks/exec"
+	"github.com/nduyhai/gocraft/internal/core/ports"
+)
+
+func TestHook_StopsAtFirstFailure(t *testing.T) {
+	var ran []string
+	step := func(name string, err error) hookexec.Step {
+		return hookexec.Step{Name: name, Run: func(dir string) (string, error) {
+			ran = append(ran, name)
+			return name + " output", err
+		}}
+	}
+	h := hookexec.New(step("a", nil), step("b", errors.New("boom")), step("c", nil))
+
+	results, err := h.Run(t.TempDir())
+	if err == nil {
+		t.Fatal("expected error from failing step")
+	}
+	if len(ran) != 2 {
+		t.Fatalf("ran = %v, want a and b only", ran)
+	}
+	want := []ports.StepStatus{ports.StepOK, ports.StepFailed, ports.StepSkipped}
+	for i, r := range results {
+		if r.Status != want[i] {
+			t.Errorf("%s status = %s, want %s", r.Name, r.Status, want[i])
+		}
+	}
+	if results[1].Output != "b output" {
+		t.Errorf("failed step output = %q", results[1].Output)
+	}
+}
+
+func TestCommand_QuotesArgs(t *testing.T) {
+	if got := hookexec.Command("git", "commit", "-m", "chore: init").Name; got != `git commit -m "chore: init"` {
+		t.Errorf("name = %s", got)
+	}
+}
+
+func TestFromHook_DirAndEnv(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "api"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	step := hookexec.FromHook("source:x", ports.Hook{
+		Command: []string{"sh", "-c", `echo "$(basename "$PWD") $GREETING"`},
+		Dir:     "api",
+		Env:     []string{"GREETING=hi"},
+	})
+	if step.Name != `source:x: sh -c "echo \"$(basename \"$PWD\") $GREETING\""` {
+		t.Errorf("name = %s", step.Name)
+	}
+	out, err := step.Run(root)
+	if err != nil || out != "api hi" {
+		t.Fatalf("out = %q, err = %v", out, err)
+	}
+
+	escape := hookexec.FromHook("source:x", ports.Hook{Name: "escape", Command: []string{"true"}, Dir: "../.."})
+	if _, err := escape.Run(root); err == nil {
+		t.Fatal("expected error for hook dir outside the project")
+	}
+}
+
+func TestGitCommit_OnlyGivenPaths(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("GIT_AUTHOR_NAME", "t")
+	t.Setenv("GIT_AUTHOR_EMAIL", "t@t")
+	t.Setenv("GIT_COMMITTER_NAME", "t")
+	t.Setenv("GIT_COMMITTER_EMAIL", "t@t")
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", root}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return string(out)
+	}
+	write := func(name, data string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	write("README.md", "readme\n")
+	git("add", "README.md")
+	git("commit", "-qm", "init")
+	write("README.md", "edited\n")
+	write("notes.txt", "unrelated\n")
+	write("go.mod", "module example.com/app\n")
+
+	if _, err := hookexec.New(hookexec.GitCommit("chore: add x", "go.mod", "go.sum")...).Run(root); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := git("show", "--name-only", "--format=", "HEAD"); got != "go.mod\n" {
+		t.Errorf("committed %q, want go.mod only", got)
+	}
+	if got := git("status", "--porcelain"); got != " M README.md\n?? notes.txt\n" {
+		t.Errorf("status = %q", got)
+	}
+}
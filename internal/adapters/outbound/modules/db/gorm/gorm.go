@@ -3,7 +3,6 @@ package gormmodule
 import (
 	"fmt"
 	"io/fs"
-	"os"
 	"strings"
 
 	"github.com/nduyhai/gocraft/internal/core/ports"
@@ -17,7 +16,7 @@ import (
 // Conflicts: db:postgres, db:mysql (since this provides DB access via GORM)
 //
 // This module adds:
-// - internal/platform/db/gorm/ (Fx provider wiring *gorm.DB)
+// - internal/platform/db/gorm/ (DI wiring providing *gorm.DB)
 // - config defaults for gorm: driver + DSNs/paths for drivers
 // - go.mod deps for gorm and drivers
 
@@ -38,13 +37,18 @@ func (Module) Conflicts() []string { return []string{"db:postgres", "db:mysql"}
 
 func (Module) Applies(ctx ports.Ctx) bool { return true }
 
-func (Module) Apply(ctx ports.Ctx) error {
+func (m Module) Apply(ctx ports.Ctx) error {
 	// Try to add required dependencies if a GoMod editor is available
 	if gm := ctx.GoMod(); gm != nil {
 		// Always add core gorm and infra libs
 		_ = gm.Add("gorm.io/gorm", "v1.25.7-0.20240204074919-46816ad31dde")
 		_ = gm.Add("github.com/spf13/viper", "v1.20.1")
-		_ = gm.Add("go.uber.org/fx", "v1.24.0")
+		switch di, _ := ports.DIMode(ctx.Values()); di {
+		case ports.DIFx:
+			_ = gm.Add("go.uber.org/fx", "v1.24.0")
+		case ports.DIWire:
+			_ = gm.Add("github.com/google/wire", "v0.6.0")
+		}
 
 		// Add only the chosen SQL driver based on config/DSN
 		drv := strings.ToLower(strings.TrimSpace(nestedString(ctx.Values(), []string{"gorm", "driver"})))
@@ -65,35 +69,11 @@ func (Module) Apply(ctx ports.Ctx) error {
 		}
 	}
 
-	// Render templates from embedded FS
-	sub, err := fs.Sub(TemplatesFS, "templates")
-	if err != nil {
-		return fmt.Errorf("sub fs: %w", err)
-	}
-	var tfiles []ports.TmplFile
-	err = fs.WalkDir(sub, ".", func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() {
-			return nil
-		}
-		clean := strings.TrimPrefix(path, "./")
-		// Do not render defaults template to disk; it's used only for merging into config.yml
-		if clean == "config/defaults.yml.tmpl" {
-			return nil
-		}
-		b, err := fs.ReadFile(sub, path)
-		if err != nil {
-			return err
-		}
-		tfiles = append(tfiles, ports.TmplFile{Path: clean, Content: string(b)})
-		return nil
-	})
+	// Load templates (built-in, shadowed by user overrides)
+	tpl, err := ctx.Templates().Load(m.Name())
 	if err != nil {
-		return fmt.Errorf("walk: %w", err)
+		return fmt.Errorf("load templates: %w", err)
 	}
-	tpl := ports.Template{Name: "db:gorm", Files: tfiles}
 	files, err := ctx.Renderer().Render(tpl, ctx.Values())
 	if err != nil {
 		return fmt.Errorf("render: %w", err)
@@ -109,27 +89,38 @@ func (Module) Apply(ctx ports.Ctx) error {
 		if modPath == "" {
 			return fmt.Errorf("module path missing in context")
 		}
-		if err := ed.Ensure("gormdb", modPath+"/internal/platform/db/gorm", "gormdb.Module()"); err != nil {
+		if err := ed.EnsureModule("gormdb", modPath+"/internal/platform/db/gorm"); err != nil {
 			return fmt.Errorf("update di root: %w", err)
 		}
 	}
 
-	// Respect --set gorm.driver=... by updating config/config.yml if provided.
+	// Respect --set gorm.driver=... by updating config/config.yml (and the local DSN) if provided.
 	drv := nestedString(ctx.Values(), []string{"gorm", "driver"})
-	if drv != "" {
-		_ = ensureGormDriverInConfig(ctx.ProjectRoot(), drv)
-	} else {
+	if drv == "" {
 		// If no driver but DSN present, infer and set it in config
-		dsn := nestedString(ctx.Values(), []string{"gorm", "dsn"})
-		if dsn != "" {
-			if inf := driverFromDSN(dsn); inf != "" {
-				_ = ensureGormDriverInConfig(ctx.ProjectRoot(), inf)
-			}
+		if dsn := nestedString(ctx.Values(), []string{"gorm", "dsn"}); dsn != "" {
+			drv = driverFromDSN(dsn)
+		}
+	}
+	if cfg := ctx.Config(); cfg != nil && drv != "" {
+		// The registry merges this module's defaults after Apply; add them first so the
+		// driver is set inside the documented gorm section.
+		if err := cfg.EnsureDefaults(m); err != nil {
+			return fmt.Errorf("config defaults: %w", err)
+		}
+		if err := ensureGormDriverInConfig(cfg, drv); err != nil {
+			return fmt.Errorf("update config: %w", err)
 		}
 	}
 	return nil
 }
 
+// Templates implements ports.TemplateSource.
+func (Module) Templates() fs.FS {
+	sub, _ := fs.Sub(TemplatesFS, "templates")
+	return sub
+}
+
 // Defaults implements ports.Module.Defaults to provide default configuration.
 func (Module) Defaults() map[string]any {
 	// Load static defaults from embedded YAML if present
@@ -171,58 +162,30 @@ func nestedString(m map[string]any, path []string) string {
 	return ""
 }
 
-func ensureGormDriverInConfig(root, driver string) error {
-	p := root + "/config/config.yml"
-	b, err := os.ReadFile(p)
-	if err != nil {
+// ensureGormDriverInConfig sets gorm.driver in config.yml and, when empty or meant for another
+// driver, gorm.dsn in the local profile, leaving the rest of the config files as they are.
+func ensureGormDriverInConfig(cfg ports.ConfigEditor, driver string) error {
+	driver = canonicalDriver(driver)
+	if err := cfg.Set("gorm.driver", driver); err != nil {
 		return err
 	}
-	var current map[string]any
-	if len(b) > 0 {
-		_ = yaml.Unmarshal(b, &current)
-	}
-	if current == nil {
-		current = make(map[string]any)
-	}
-	// Set desired driver
-	setPath(current, []string{"gorm", "driver"}, driver)
 	// Adjust DSN if empty or incompatible with selected driver
-	var curDSN string
-	if g, ok := current["gorm"].(map[string]any); ok {
-		if v, ok := g["dsn"].(string); ok {
-			curDSN = strings.TrimSpace(v)
-		}
-	}
+	local := cfg.Profile("local")
+	curDSN, _ := local.Get("gorm.dsn")
+	curDSN = strings.TrimSpace(curDSN)
 	if curDSN == "" || driverFromDSN(curDSN) != strings.ToLower(strings.TrimSpace(driver)) {
-		setPath(current, []string{"gorm", "dsn"}, defaultDSNFor(driver))
-	}
-	out, err := yaml.Marshal(current)
-	if err != nil {
-		return err
+		return local.Set("gorm.dsn", defaultDSNFor(driver))
 	}
-	return os.WriteFile(p, out, 0o644)
+	return nil
 }
 
-func setPath(m map[string]any, path []string, value any) {
-	cur := m
-	for i, p := range path {
-		if i == len(path)-1 {
-			cur[p] = value
-			return
-		}
-		next, ok := cur[p]
-		if !ok {
-			nm := make(map[string]any)
-			cur[p] = nm
-			cur = nm
-			continue
-		}
-		nm, ok := next.(map[string]any)
-		if !ok {
-			nm = make(map[string]any)
-			cur[p] = nm
-		}
-		cur = nm
+// canonicalDriver maps driver aliases (pg, postgresql) to the names the config schema allows.
+func canonicalDriver(driver string) string {
+	switch d := strings.ToLower(strings.TrimSpace(driver)); d {
+	case "postgres", "pg", "postgre", "postgresql":
+		return "postgres"
+	default:
+		return d
 	}
 }
 
@@ -3,7 +3,6 @@ package makefile
 import (
 	"fmt"
 	"io/fs"
-	"strings"
 
 	"github.com/nduyhai/gocraft/internal/core/ports"
 )
@@ -33,32 +32,12 @@ func (Module) Conflicts() []string { return nil }
 
 func (Module) Applies(ctx ports.Ctx) bool { return true }
 
-func (Module) Apply(ctx ports.Ctx) error {
-	// Build template from embedded TemplatesFS
-	sub, err := fs.Sub(TemplatesFS, "templates")
+func (m Module) Apply(ctx ports.Ctx) error {
+	// Load templates (built-in, shadowed by user overrides)
+	tpl, err := ctx.Templates().Load(m.Name())
 	if err != nil {
-		return fmt.Errorf("sub fs: %w", err)
+		return fmt.Errorf("load templates: %w", err)
 	}
-	var tfiles []ports.TmplFile
-	err = fs.WalkDir(sub, ".", func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() {
-			return nil
-		}
-		b, err := fs.ReadFile(sub, path)
-		if err != nil {
-			return err
-		}
-		clean := strings.TrimPrefix(path, "./")
-		tfiles = append(tfiles, ports.TmplFile{Path: clean, Content: string(b)})
-		return nil
-	})
-	if err != nil {
-		return fmt.Errorf("walk: %w", err)
-	}
-	tpl := ports.Template{Name: "feature:makefile", Files: tfiles}
 	files, err := ctx.Renderer().Render(tpl, ctx.Values())
 	if err != nil {
 		return fmt.Errorf("render: %w", err)
@@ -69,5 +48,11 @@ func (Module) Apply(ctx ports.Ctx) error {
 	return nil
 }
 
+// Templates implements ports.TemplateSource.
+func (Module) Templates() fs.FS {
+	sub, _ := fs.Sub(TemplatesFS, "templates")
+	return sub
+}
+
 // Defaults returns no defaults for this feature module.
 func (Module) Defaults() map[string]any { return nil }
@@ -3,7 +3,7 @@ package grpcservermodule
 import (
 	"fmt"
 	"io/fs"
-	"strings"
+	"path/filepath"
 
 	"github.com/nduyhai/gocraft/internal/core/ports"
 	"gopkg.in/yaml.v3"
@@ -12,13 +12,14 @@ import (
 // Module implements ports.Module for adding a gRPC server into an existing or new project.
 //
 // Name:     grpc:server
-// Requires: platform:base (for project structure and Fx)
+// Requires: platform:base (for project structure and the DI root)
 // Conflicts: none
 //
 // This module adds:
 // - internal/adapters/inbound/grpc/server/ (server wiring)
 // - Registers google.golang.org/grpc/health checking service by default
 // - Minimal config via Viper with default addr ":9090"
+// - A post-generate `buf generate` hook when the project contains a buf.gen.yaml
 
 type Module struct{}
 
@@ -37,38 +38,23 @@ func (Module) Conflicts() []string { return nil }
 
 func (Module) Applies(ctx ports.Ctx) bool { return true }
 
-func (Module) Apply(ctx ports.Ctx) error {
+func (m Module) Apply(ctx ports.Ctx) error {
 	// Try to add required dependencies if a GoMod editor is available
 	if gm := ctx.GoMod(); gm != nil {
 		_ = gm.Add("google.golang.org/grpc", "v1.63.2")
 		_ = gm.Add("github.com/spf13/viper", "v1.20.1")
-		_ = gm.Add("go.uber.org/fx", "v1.24.0")
-	}
-	// Build template from embedded TemplatesFS
-	sub, err := fs.Sub(TemplatesFS, "templates")
-	if err != nil {
-		return fmt.Errorf("sub fs: %w", err)
-	}
-	var tfiles []ports.TmplFile
-	err = fs.WalkDir(sub, ".", func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() {
-			return nil
+		switch di, _ := ports.DIMode(ctx.Values()); di {
+		case ports.DIFx:
+			_ = gm.Add("go.uber.org/fx", "v1.24.0")
+		case ports.DIWire:
+			_ = gm.Add("github.com/google/wire", "v0.6.0")
 		}
-		b, err := fs.ReadFile(sub, path)
-		if err != nil {
-			return err
-		}
-		clean := strings.TrimPrefix(path, "./")
-		tfiles = append(tfiles, ports.TmplFile{Path: clean, Content: string(b)})
-		return nil
-	})
+	}
+	// Load templates (built-in, shadowed by user overrides)
+	tpl, err := ctx.Templates().Load(m.Name())
 	if err != nil {
-		return fmt.Errorf("walk: %w", err)
+		return fmt.Errorf("load templates: %w", err)
 	}
-	tpl := ports.Template{Name: "grpc:server", Files: tfiles}
 	files, err := ctx.Renderer().Render(tpl, ctx.Values())
 	if err != nil {
 		return fmt.Errorf("render: %w", err)
@@ -84,13 +70,36 @@ func (Module) Apply(ctx ports.Ctx) error {
 		if modPath == "" {
 			return fmt.Errorf("module path missing in context")
 		}
-		if err := ed.Ensure("grpcserver", modPath+"/internal/adapters/inbound/grpc/server", "grpcserver.Module()"); err != nil {
+		if err := ed.EnsureModule("grpcserver", modPath+"/internal/adapters/inbound/grpc/server"); err != nil {
 			return fmt.Errorf("update di root: %w", err)
 		}
 	}
 	return nil
 }
 
+// Hooks implements ports.HookProvider: projects that ship a buf.gen.yaml (e.g. from a
+// template source) get their protobuf code generated once the files are on disk.
+func (Module) Hooks(ctx ports.Ctx) []ports.Hook {
+	fsys := ctx.Files()
+	if fsys == nil {
+		return nil
+	}
+	if _, err := fsys.Stat(filepath.Join(ctx.ProjectRoot(), "buf.gen.yaml")); err != nil {
+		return nil
+	}
+	return []ports.Hook{{
+		Name:    "buf generate",
+		Stage:   ports.HookPostGenerate,
+		Command: []string{"buf", "generate"},
+	}}
+}
+
+// Templates implements ports.TemplateSource.
+func (Module) Templates() fs.FS {
+	sub, _ := fs.Sub(TemplatesFS, "templates")
+	return sub
+}
+
 // Defaults implements ports.Module.Defaults to provide default configuration.
 func (Module) Defaults() map[string]any {
 	// Attempt to load from embedded defaults template
@@ -3,7 +3,6 @@ package chimodule
 import (
 	"fmt"
 	"io/fs"
-	"strings"
 
 	"github.com/nduyhai/gocraft/internal/core/ports"
 	"gopkg.in/yaml.v3"
@@ -12,7 +11,7 @@ import (
 // Module implements ports.Module for adding a Chi HTTP server into an existing or new project.
 //
 // Name:      http:chi
-// Requires:  platform:base (for project structure and Fx)
+// Requires:  platform:base (for project structure and the DI root)
 // Conflicts: http:gin
 //
 // This module adds:
@@ -38,40 +37,25 @@ func (Module) Conflicts() []string { return []string{"http:gin"} }
 
 func (Module) Applies(ctx ports.Ctx) bool { return true }
 
-func (Module) Apply(ctx ports.Ctx) error {
+func (m Module) Apply(ctx ports.Ctx) error {
 	// Try to add required dependencies if a GoMod editor is available
 	if gm := ctx.GoMod(); gm != nil {
 		_ = gm.Add("github.com/go-chi/chi/v5", "v5.0.12")
 		_ = gm.Add("github.com/google/uuid", "v1.6.0")
 		_ = gm.Add("github.com/prometheus/client_golang", "v1.19.1")
 		_ = gm.Add("github.com/spf13/viper", "v1.20.1")
-		_ = gm.Add("go.uber.org/fx", "v1.24.0")
-	}
-	// Build template from embedded TemplatesFS
-	sub, err := fs.Sub(TemplatesFS, "templates")
-	if err != nil {
-		return fmt.Errorf("sub fs: %w", err)
-	}
-	var tfiles []ports.TmplFile
-	err = fs.WalkDir(sub, ".", func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() {
-			return nil
+		switch di, _ := ports.DIMode(ctx.Values()); di {
+		case ports.DIFx:
+			_ = gm.Add("go.uber.org/fx", "v1.24.0")
+		case ports.DIWire:
+			_ = gm.Add("github.com/google/wire", "v0.6.0")
 		}
-		b, err := fs.ReadFile(sub, path)
-		if err != nil {
-			return err
-		}
-		clean := strings.TrimPrefix(path, "./")
-		tfiles = append(tfiles, ports.TmplFile{Path: clean, Content: string(b)})
-		return nil
-	})
+	}
+	// Load templates (built-in, shadowed by user overrides)
+	tpl, err := ctx.Templates().Load(m.Name())
 	if err != nil {
-		return fmt.Errorf("walk: %w", err)
+		return fmt.Errorf("load templates: %w", err)
 	}
-	tpl := ports.Template{Name: "http:chi", Files: tfiles}
 	files, err := ctx.Renderer().Render(tpl, ctx.Values())
 	if err != nil {
 		return fmt.Errorf("render: %w", err)
@@ -87,13 +71,19 @@ func (Module) Apply(ctx ports.Ctx) error {
 		if modPath == "" {
 			return fmt.Errorf("module path missing in context")
 		}
-		if err := ed.Ensure("httpchi", modPath+"/internal/adapters/inbound/http/chi", "httpchi.Module()"); err != nil {
+		if err := ed.EnsureModule("httpchi", modPath+"/internal/adapters/inbound/http/chi"); err != nil {
 			return fmt.Errorf("update di root: %w", err)
 		}
 	}
 	return nil
 }
 
+// Templates implements ports.TemplateSource.
+func (Module) Templates() fs.FS {
+	sub, _ := fs.Sub(TemplatesFS, "templates")
+	return sub
+}
+
 // Defaults implements ports.Module.Defaults to provide default configuration.
 func (Module) Defaults() map[string]any {
 	// Attempt to load from embedded defaults template
@@ -3,14 +3,16 @@ package base
 import (
 	"fmt"
 	"io/fs"
-	"strings"
+	"path/filepath"
 
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/templates/overlay"
 	"github.com/nduyhai/gocraft/internal/core/ports"
 )
 
 // Module implements ports.Module for the base platform.
 // It generates a base project layout (Fx + Viper, logger, DI root, basic core/adapters skeleton)
-// using the embedded "basic" template.
+// using the embedded "basic" template; the di value switches the DI root to Wire or to a
+// hand-written one.
 //
 // Name:     platform:base
 // Requires: none
@@ -24,7 +26,7 @@ func (Module) Name() string    { return "platform:base" }
 func (Module) Label() string   { return "Platform Base (Fx + Viper)" }
 func (Module) Version() string { return "0.1.0" }
 func (Module) Summary() string {
-	return "Generates base clean-arch project (Fx DI, Viper config, logger, DI root)"
+	return "Generates base clean-arch project (Fx, Wire or manual DI, Viper config, logger, DI root)"
 }
 func (Module) Tags() []string { return []string{"platform", "base", "fx", "viper"} }
 
@@ -32,36 +34,26 @@ func (Module) Requires() []string  { return nil }
 func (Module) Conflicts() []string { return nil }
 
 // Applies returns true if we should apply the module in the given context.
-// For now, always true; in future we could detect if files already exist to avoid overwrite.
-func (Module) Applies(ctx ports.Ctx) bool { return true }
+// It is skipped when the project already has a go.mod (e.g. `gocraft add` in an existing project).
+func (Module) Applies(ctx ports.Ctx) bool { return NewProject(ctx) }
 
-func (Module) Apply(ctx ports.Ctx) error {
-	// Build template from embedded TemplatesFS
-	sub, err := fs.Sub(TemplatesFS, "templates")
-	if err != nil {
-		return fmt.Errorf("sub fs: %w", err)
-	}
-	var tfiles []ports.TmplFile
-	err = fs.WalkDir(sub, ".", func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() {
-			return nil
-		}
-		b, err := fs.ReadFile(sub, path)
-		if err != nil {
-			return err
+// NewProject reports whether the project root has no go.mod yet, i.e. whether a project skeleton
+// such as platform:base or platform:service still has to be generated.
+func NewProject(ctx ports.Ctx) bool {
+	if fsys := ctx.Files(); fsys != nil {
+		if _, err := fsys.Stat(filepath.Join(ctx.ProjectRoot(), "go.mod")); err == nil {
+			return false
 		}
-		clean := strings.TrimPrefix(path, "./")
-		tfiles = append(tfiles, ports.TmplFile{Path: clean, Content: string(b)})
-		return nil
-	})
+	}
+	return true
+}
+
+func (m Module) Apply(ctx ports.Ctx) error {
+	// Load templates (built-in, shadowed by user overrides)
+	tpl, err := ctx.Templates().Load(m.Name())
 	if err != nil {
-		return fmt.Errorf("walk: %w", err)
+		return fmt.Errorf("load templates: %w", err)
 	}
-	tpl := ports.Template{Name: "basic", Files: tfiles}
-	// Render with provided values (.Name, .Module)
 	files, err := ctx.Renderer().Render(tpl, ctx.Values())
 	if err != nil {
 		return fmt.Errorf("render: %w", err)
@@ -70,8 +62,28 @@ func (Module) Apply(ctx ports.Ctx) error {
 	if err := ctx.FS().WriteAll(ctx.ProjectRoot(), files); err != nil {
 		return fmt.Errorf("write: %w", err)
 	}
+	// The config templates are YAML; other formats are converted once written
+	format, err := ports.ConfigFormat(ctx.Values())
+	if err != nil {
+		return err
+	}
+	if cfg := ctx.Config(); cfg != nil && format != ports.ConfigYAML {
+		if err := cfg.Convert(format); err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+	}
 	return nil
 }
 
+// ConfigTemplates returns the config/ templates of platform:base alone; platform:service
+// generates the same config files.
+func ConfigTemplates() fs.FS { return overlay.Only(Module{}.Templates(), "config") }
+
+// Templates implements ports.TemplateSource.
+func (Module) Templates() fs.FS {
+	sub, _ := fs.Sub(TemplatesFS, "templates")
+	return sub
+}
+
 // Defaults returns no extra defaults for platform:base (config template already includes baseline settings).
 func (Module) Defaults() map[string]any { return nil }
@@ -0,0 +1,96 @@
+package monorepo
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/nduyhai/gocraft/internal/core/ports"
+)
+
+// PlatformDir is the repository-relative directory of the shared platform library.
+const PlatformDir = "platform"
+
+// ServicesDir is the repository-relative directory holding one module per service.
+const ServicesDir = "services"
+
+// Module implements ports.Module for a multi-service repository.
+// It generates a go.work workspace, an empty services/ directory and a shared platform
+// library module (env, logger, DI helpers, HTTP middlewares) that services created with
+// platform:service import instead of carrying their own copies.
+//
+// Name:     platform:monorepo
+// Requires: none
+// Conflicts: platform:base
+type Module struct{}
+
+func New() Module { return Module{} }
+
+func (Module) Name() string    { return "platform:monorepo" }
+func (Module) Label() string   { return "Monorepo Platform" }
+func (Module) Version() string { return "0.1.0" }
+func (Module) Summary() string {
+	return "Generates a go.work monorepo with services/ and a shared platform library (env, logger, DI, middleware)"
+}
+func (Module) Tags() []string { return []string{"platform", "monorepo", "workspace"} }
+
+func (Module) Requires() []string  { return nil }
+func (Module) Conflicts() []string { return []string{"platform:base"} }
+
+// Applies returns false when the repository already has a go.work.
+func (Module) Applies(ctx ports.Ctx) bool {
+	if fsys := ctx.Files(); fsys != nil {
+		if _, err := fsys.Stat(filepath.Join(ctx.ProjectRoot(), "go.work")); err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (m Module) Apply(ctx ports.Ctx) error {
+	// Services share the platform's Fx modules
+	if di, _ := ports.DIMode(ctx.Values()); di != ports.DIFx {
+		return fmt.Errorf("di=%s: monorepo services are wired with %s only", di, ports.DIFx)
+	}
+	tpl, err := ctx.Templates().Load(m.Name())
+	if err != nil {
+		return fmt.Errorf("load templates: %w", err)
+	}
+	files, err := ctx.Renderer().Render(tpl, ctx.Values())
+	if err != nil {
+		return fmt.Errorf("render: %w", err)
+	}
+	if err := ctx.FS().WriteAll(ctx.ProjectRoot(), files); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return recordConfigFormat(ctx)
+}
+
+// recordConfigFormat writes a non-default config.format to the repository's .gocraft.yaml, so
+// that `gocraft service add` generates services in the format the shared env.Load reads.
+// An existing .gocraft.yaml is left alone.
+func recordConfigFormat(ctx ports.Ctx) error {
+	format, err := ports.ConfigFormat(ctx.Values())
+	if err != nil || format == ports.ConfigYAML {
+		return err
+	}
+	fsys := ctx.Files()
+	if fsys == nil {
+		return nil
+	}
+	p := filepath.Join(ctx.ProjectRoot(), ".gocraft.yaml")
+	if _, err := fsys.Stat(p); err == nil {
+		return nil
+	}
+	data := fmt.Sprintf("values:\n  config:\n    format: %s\n", format)
+	return fsys.WriteFile(p, []byte(data), 0o644)
+}
+
+// Templates implements ports.TemplateSource.
+func (Module) Templates() fs.FS {
+	sub, _ := fs.Sub(TemplatesFS, "templates")
+	return sub
+}
+
+// Defaults returns no config defaults; each service carries its own config.
+func (Module) Defaults() map[string]any { return nil }
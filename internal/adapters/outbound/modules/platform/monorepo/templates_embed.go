@@ -0,0 +1,10 @@
+package monorepo
+
+import "embed"
+
+// TemplatesFS embeds the repository skeleton and the shared platform library.
+//
+//go:embed templates/*.tmpl
+//go:embed templates/platform/**
+//go:embed templates/services/.gitkeep
+var TemplatesFS embed.FS
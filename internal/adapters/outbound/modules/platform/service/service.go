@@ -0,0 +1,81 @@
+package service
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/modules/platform/base"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/templates/overlay"
+	"github.com/nduyhai/gocraft/internal/core/ports"
+)
+
+// Module implements ports.Module for a service inside a platform:monorepo repository.
+// It generates the same layout as platform:base, except that config, logging and DI helpers
+// come from the shared platform library (value "Platform", its module path), which the
+// service requires through a replace to ../../platform.
+//
+// Name:     platform:service
+// Requires: none
+// Conflicts: platform:base
+//
+// Other modules still require platform:base; it is skipped because the service's go.mod exists
+// once this module has been applied, so apply platform:service first.
+type Module struct{}
+
+func New() Module { return Module{} }
+
+func (Module) Name() string    { return "platform:service" }
+func (Module) Label() string   { return "Monorepo Service" }
+func (Module) Version() string { return "0.1.0" }
+func (Module) Summary() string {
+	return "Generates a service module using the monorepo's shared platform library"
+}
+func (Module) Tags() []string { return []string{"platform", "monorepo", "service"} }
+
+func (Module) Requires() []string  { return nil }
+func (Module) Conflicts() []string { return []string{"platform:base"} }
+
+// Applies returns false when the service already has a go.mod.
+func (Module) Applies(ctx ports.Ctx) bool { return base.NewProject(ctx) }
+
+func (m Module) Apply(ctx ports.Ctx) error {
+	if p, _ := ctx.Values()["Platform"].(string); p == "" {
+		return fmt.Errorf("platform module path missing in context")
+	}
+	// Services share the platform's Fx modules
+	if di, _ := ports.DIMode(ctx.Values()); di != ports.DIFx {
+		return fmt.Errorf("di=%s: monorepo services are wired with %s only", di, ports.DIFx)
+	}
+	tpl, err := ctx.Templates().Load(m.Name())
+	if err != nil {
+		return fmt.Errorf("load templates: %w", err)
+	}
+	files, err := ctx.Renderer().Render(tpl, ctx.Values())
+	if err != nil {
+		return fmt.Errorf("render: %w", err)
+	}
+	if err := ctx.FS().WriteAll(ctx.ProjectRoot(), files); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	// The config templates are YAML; other formats are converted once written
+	format, err := ports.ConfigFormat(ctx.Values())
+	if err != nil {
+		return err
+	}
+	if cfg := ctx.Config(); cfg != nil && format != ports.ConfigYAML {
+		if err := cfg.Convert(format); err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+	}
+	return nil
+}
+
+// Templates implements ports.TemplateSource: the service's own templates plus the config
+// templates of platform:base, which services share unchanged.
+func (Module) Templates() fs.FS {
+	sub, _ := fs.Sub(TemplatesFS, "templates")
+	return overlay.Union(sub, base.ConfigTemplates())
+}
+
+// Defaults returns no extra defaults (the config template already includes baseline settings).
+func (Module) Defaults() map[string]any { return nil }
@@ -0,0 +1,11 @@
+package service
+
+import "embed"
+
+// TemplatesFS embeds the service skeleton, including the underscored __name__ directory.
+// The config templates are platform:base's (see Module.Templates).
+//
+//go:embed templates/*.tmpl
+//go:embed templates/internal/platform/**
+//go:embed templates/cmd/__name__/**
+var TemplatesFS embed.FS
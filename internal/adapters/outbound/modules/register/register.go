@@ -9,6 +9,8 @@ import (
 	chimodule "github.com/nduyhai/gocraft/internal/adapters/outbound/modules/http/chi"
 	ginmodule "github.com/nduyhai/gocraft/internal/adapters/outbound/modules/http/gin"
 	"github.com/nduyhai/gocraft/internal/adapters/outbound/modules/platform/base"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/modules/platform/monorepo"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/modules/platform/service"
 	"github.com/nduyhai/gocraft/internal/core/ports"
 )
 
@@ -21,6 +23,9 @@ import (
 func Builtins(r ports.Registry) {
 	// Platform base module (Fx + Viper, logger, DI root, basic structure)
 	r.Register(base.New())
+	// Monorepo layout: repository with shared platform library, and services using it
+	r.Register(monorepo.New())
+	r.Register(service.New())
 	// HTTP server modules
 	r.Register(ginmodule.New())
 	r.Register(chimodule.New())
@@ -0,0 +1,98 @@
+package sourcemodule
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// gitPrefix marks a git repository source, e.g. git+file:///srv/templates.git#v2
+// or git+https://git.example.com/kits/service.git#main.
+const gitPrefix = "git+"
+
+// IsRemote reports whether from names a git repository rather than a local directory.
+func IsRemote(from string) bool { return strings.HasPrefix(from, gitPrefix) }
+
+// Resolve turns a --from reference into a local directory holding a template set.
+// Local paths are returned as-is; git sources are cloned into a temporary directory
+// that cleanup removes. The optional #fragment selects a branch, tag or commit.
+func Resolve(from string) (dir string, cleanup func(), err error) {
+	cleanup = func() {}
+	if !IsRemote(from) {
+		fi, err := os.Stat(from)
+		if err != nil {
+			return "", cleanup, fmt.Errorf("template source: %w", err)
+		}
+		if !fi.IsDir() {
+			return "", cleanup, fmt.Errorf("template source %s is not a directory", from)
+		}
+		return from, cleanup, nil
+	}
+
+	url, ref, _ := strings.Cut(strings.TrimPrefix(from, gitPrefix), "#")
+	// Neither may pass for a git option (e.g. --upload-pack=<command>)
+	if url == "" || strings.HasPrefix(url, "-") {
+		return "", cleanup, fmt.Errorf("template source %s: invalid repository %q", from, url)
+	}
+	if strings.HasPrefix(ref, "-") {
+		return "", cleanup, fmt.Errorf("template source %s: invalid ref %q", from, ref)
+	}
+	tmp, err := os.MkdirTemp("", "gocraft-source-*")
+	if err != nil {
+		return "", cleanup, err
+	}
+	cleanup = func() { _ = os.RemoveAll(tmp) }
+	// The clone is named after the repository, which names a manifest without one
+	dir = filepath.Join(tmp, repoName(url))
+
+	// Shallow clone works for branches and tags; fall back to a full clone + checkout for commits.
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	if _, err := git("", append(args, "--", url, dir)...); err != nil {
+		if ref == "" {
+			cleanup()
+			return "", func() {}, err
+		}
+		_ = os.RemoveAll(dir)
+		if _, err := git("", "clone", "--quiet", "--", url, dir); err != nil {
+			cleanup()
+			return "", func() {}, err
+		}
+		if _, err := git(dir, "checkout", "--quiet", ref, "--"); err != nil {
+			cleanup()
+			return "", func() {}, err
+		}
+	}
+	return dir, cleanup, nil
+}
+
+// repoName returns the last path element of a git URL without its .git suffix, e.g.
+// "templates" for file:///srv/templates.git and "service" for git@example.com:kits/service.git.
+func repoName(url string) string {
+	url = strings.TrimRight(url, "/")
+	if i := strings.LastIndexAny(url, "/:"); i >= 0 {
+		url = url[i+1:]
+	}
+	name := strings.TrimSuffix(url, ".git")
+	if name == "" || name == "." || name == ".." {
+		return "src"
+	}
+	return name
+}
+
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return out.String(), fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(out.String()))
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,274 @@
+package sourcemodule
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nduyhai/gocraft/internal/core/ports"
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile is the manifest expected at the root of a template source.
+const ManifestFile = "gocraft.yaml"
+
+// Manifest describes an external template set (a directory or git repository).
+//
+//	name: company-starter
+//	summary: Company service starter kit
+//	values:            # defaults for template values; --set wins
+//	  team: payments
+//	requires:          # modules applied before this template set
+//	  - http:chi
+//	go:
+//	  require:
+//	    github.com/acme/kit: v1.4.0
+//	di:                # entries added to internal/platform/di/root.go
+//	  - alias: billing
+//	    import: internal/billing   # relative imports are prefixed with the project module path
+//	    expr: billing.Module()
+//	  - kind: provide              # option (default), provide, invoke or decorate
+//	    alias: billing
+//	    import: internal/billing
+//	    expr: billing.NewInvoiceRepo
+//	    as: [ports.InvoiceRepo]    # fx.As
+//	    imports: {ports: internal/core/ports} # further packages the entry refers to
+//	    group: repos               # value group
+//	    into: adapters             # option function of root.go, created when missing
+//	  - kind: invoke
+//	    expr: registerRoutes
+//	    file: internal/adapters/inbound/module.go # instead of root.go; created when missing
+//	    func: Module               # the function whose fx.Options(...) gets the entry
+//	hooks:             # commands run after the project is written (skip with --skip-hooks)
+//	  - name: generate protobuf code
+//	    run: [buf, generate]
+//	    dir: api
+//	    env: [BUF_CACHE_DIR=.cache/buf]
+type Manifest struct {
+	Name     string         `yaml:"name"` // default: the directory or git repository name
+	Summary  string         `yaml:"summary"`
+	Version  string         `yaml:"version"`
+	Values   map[string]any `yaml:"values"`
+	Requires []string       `yaml:"requires"`
+	Go       struct {
+		Require map[string]string `yaml:"require"`
+	} `yaml:"go"`
+	DI    []DIEntry   `yaml:"di"`
+	Hooks []HookEntry `yaml:"hooks"`
+}
+
+// DIEntry is an fx option, constructor, invoke or decorator the template set registers in the
+// DI root (see ports.DIEntry).
+type DIEntry struct {
+	Kind   string   `yaml:"kind"`
+	Alias  string   `yaml:"alias"`
+	Import string   `yaml:"import"`
+	Expr   string   `yaml:"expr"`
+	As     []string `yaml:"as"`
+	Group  string   `yaml:"group"`
+	Into   string   `yaml:"into"`
+	File   string   `yaml:"file"`
+	Func   string   `yaml:"func"`
+	// Imports maps further aliases the entry uses (in As, say) to their import paths.
+	Imports map[string]string `yaml:"imports"`
+}
+
+// HookEntry is a post-generate command declared by the template set.
+type HookEntry struct {
+	Name string   `yaml:"name"`
+	Run  []string `yaml:"run"`
+	Dir  string   `yaml:"dir"`
+	Env  []string `yaml:"env"`
+}
+
+// Module implements ports.Module for an ad-hoc template set loaded from disk.
+//
+// Name:      source:<manifest name>
+// Requires:  platform:base plus the manifest's requires
+// Conflicts: none
+//
+// Templates are read from <dir>/templates when present, otherwise from <dir> itself
+// (ignoring the manifest and .git). They are rendered like built-in module templates,
+// including path tokens such as __name__ and __module__.
+type Module struct {
+	dir      string
+	manifest Manifest
+}
+
+// Load reads the manifest in dir and returns the template set as a module.
+func Load(dir string) (*Module, error) {
+	b, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%s not found in %s", ManifestFile, dir)
+		}
+		return nil, err
+	}
+	var man Manifest
+	if err := yaml.Unmarshal(b, &man); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", ManifestFile, err)
+	}
+	if strings.TrimSpace(man.Name) == "" {
+		man.Name = filepath.Base(filepath.Clean(dir))
+	}
+	for i, h := range man.Hooks {
+		if len(h.Run) == 0 {
+			return nil, fmt.Errorf("%s: hooks[%d] has no run command", ManifestFile, i)
+		}
+	}
+	return &Module{dir: dir, manifest: man}, nil
+}
+
+func (m *Module) Name() string  { return "source:" + m.manifest.Name }
+func (m *Module) Label() string { return "Template source " + m.manifest.Name }
+func (m *Module) Version() string {
+	if m.manifest.Version == "" {
+		return "0.0.0"
+	}
+	return m.manifest.Version
+}
+func (m *Module) Summary() string {
+	if m.manifest.Summary == "" {
+		return "Templates loaded from " + m.dir
+	}
+	return m.manifest.Summary
+}
+func (m *Module) Tags() []string { return []string{"source"} }
+
+func (m *Module) Requires() []string {
+	return append([]string{"platform:base"}, m.manifest.Requires...)
+}
+func (m *Module) Conflicts() []string { return nil }
+
+func (m *Module) Applies(ctx ports.Ctx) bool { return true }
+
+// Values returns the manifest's default template values.
+func (m *Module) Values() map[string]any { return m.manifest.Values }
+
+// Commands returns the manifest's post-generate commands.
+func (m *Module) Commands() []HookEntry { return m.manifest.Hooks }
+
+func (m *Module) Apply(ctx ports.Ctx) error {
+	if gm := ctx.GoMod(); gm != nil {
+		reqs := make([]string, 0, len(m.manifest.Go.Require))
+		for path := range m.manifest.Go.Require {
+			reqs = append(reqs, path)
+		}
+		sort.Strings(reqs)
+		for _, path := range reqs {
+			if err := gm.Add(path, m.manifest.Go.Require[path]); err != nil {
+				return fmt.Errorf("go.mod require %s: %w", path, err)
+			}
+		}
+	}
+
+	tpl, err := ctx.Templates().Load(m.Name())
+	if err != nil {
+		return fmt.Errorf("load templates: %w", err)
+	}
+	files, err := ctx.Renderer().Render(tpl, ctx.Values())
+	if err != nil {
+		return fmt.Errorf("render: %w", err)
+	}
+	if err := ctx.FS().WriteAll(ctx.ProjectRoot(), files); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	if ed := ctx.AdaptersModule(); ed != nil && len(m.manifest.DI) > 0 {
+		modPath, _ := ctx.Values()["Module"].(string)
+		if modPath == "" {
+			return fmt.Errorf("module path missing in context")
+		}
+		for _, e := range m.manifest.DI {
+			entry := ports.DIEntry{
+				Kind: ports.DIKind(e.Kind), Expr: e.Expr, As: e.As, Group: e.Group, Into: e.Into, File: e.File, Func: e.Func,
+			}
+			if e.Import != "" {
+				entry.Imports = append(entry.Imports, ports.DIImport{Alias: e.Alias, Path: projectImport(modPath, e.Import)})
+			}
+			aliases := make([]string, 0, len(e.Imports))
+			for alias := range e.Imports {
+				aliases = append(aliases, alias)
+			}
+			sort.Strings(aliases)
+			for _, alias := range aliases {
+				entry.Imports = append(entry.Imports, ports.DIImport{Alias: alias, Path: projectImport(modPath, e.Imports[alias])})
+			}
+			if err := ed.EnsureEntry(entry); err != nil {
+				return fmt.Errorf("update di root: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// projectImport prefixes a relative import path (one whose first element has no dot) with the
+// project module path.
+func projectImport(modPath, imp string) string {
+	if !strings.Contains(strings.SplitN(imp, "/", 2)[0], ".") {
+		return modPath + "/" + strings.TrimPrefix(imp, "./")
+	}
+	return imp
+}
+
+// Hooks implements ports.HookProvider with the manifest's post-generate commands.
+func (m *Module) Hooks(ctx ports.Ctx) []ports.Hook {
+	hooks := make([]ports.Hook, 0, len(m.manifest.Hooks))
+	for _, h := range m.manifest.Hooks {
+		hooks = append(hooks, ports.Hook{
+			Name:    h.Name,
+			Stage:   ports.HookPostGenerate,
+			Command: h.Run,
+			Dir:     h.Dir,
+			Env:     h.Env,
+		})
+	}
+	return hooks
+}
+
+// Templates implements ports.TemplateSource.
+func (m *Module) Templates() fs.FS {
+	if fi, err := os.Stat(filepath.Join(m.dir, "templates")); err == nil && fi.IsDir() {
+		return os.DirFS(filepath.Join(m.dir, "templates"))
+	}
+	return rootFS{FS: os.DirFS(m.dir)}
+}
+
+// Defaults returns no defaults for template sources.
+func (m *Module) Defaults() map[string]any { return nil }
+
+// rootFS hides the manifest and VCS metadata when templates live at the source root.
+type rootFS struct{ fs.FS }
+
+func (r rootFS) Open(name string) (fs.File, error) {
+	if hidden(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return r.FS.Open(name)
+}
+
+func (r rootFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(r.FS, name)
+	if err != nil {
+		return nil, err
+	}
+	out := entries[:0]
+	for _, e := range entries {
+		if !hidden(filepath.ToSlash(filepath.Join(name, e.Name()))) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func hidden(name string) bool {
+	return name == ManifestFile || name == ".git" || strings.HasPrefix(name, ".git/")
+}
+
+var _ ports.Module = (*Module)(nil)
+var _ ports.TemplateSource = (*Module)(nil)
+var _ ports.HookProvider = (*Module)(nil)
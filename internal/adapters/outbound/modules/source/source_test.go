@@ -0,0 +1,108 @@
+package sourcemodule_test
+
+import (
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	sourcemodule "github.com/nduyhai/gocraft/internal/adapters/outbound/modules/source"
+)
+
+func TestLoad_ManifestAndTemplates(t *testing.T) {
+	dir := t.TempDir()
+	mustWrite(t, filepath.Join(dir, sourcemodule.ManifestFile), "name: company\nrequires: [http:chi]\nvalues:\n  team: payments\n")
+	mustWrite(t, filepath.Join(dir, "internal", "billing", "module.go.tmpl"), "package billing\n")
+	mustWrite(t, filepath.Join(dir, ".git", "HEAD"), "ref: refs/heads/main\n")
+
+	m, err := sourcemodule.Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if m.Name() != "source:company" {
+		t.Fatalf("Name = %q", m.Name())
+	}
+	if got := m.Requires(); len(got) != 2 || got[0] != "platform:base" || got[1] != "http:chi" {
+		t.Fatalf("Requires = %v", got)
+	}
+	if m.Values()["team"] != "payments" {
+		t.Fatalf("Values = %v", m.Values())
+	}
+
+	var files []string
+	err = fs.WalkDir(m.Templates(), ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walk: %v", err)
+	}
+	if len(files) != 1 || files[0] != "internal/billing/module.go.tmpl" {
+		t.Fatalf("templates = %v, want only internal/billing/module.go.tmpl", files)
+	}
+}
+
+func TestLoad_MissingManifest(t *testing.T) {
+	if _, err := sourcemodule.Load(t.TempDir()); err == nil {
+		t.Fatal("expected error for missing manifest")
+	}
+}
+
+func TestResolve_GitSourceNamedAfterRepository(t *testing.T) {
+	repo := filepath.Join(t.TempDir(), "templates.git")
+	mustWrite(t, filepath.Join(repo, sourcemodule.ManifestFile), "summary: unnamed\n")
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"-c", "user.name=t", "-c", "user.email=t@t", "commit", "-qm", "init"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	dir, cleanup, err := sourcemodule.Resolve("git+file://" + repo)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	defer cleanup()
+	m, err := sourcemodule.Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if m.Name() != "source:templates" {
+		t.Fatalf("Name = %q, want source:templates", m.Name())
+	}
+}
+
+func TestResolve_RejectsOptionLikeURLAndRef(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "pwned")
+	for _, from := range []string{
+		"git+--upload-pack=touch " + marker,
+		"git+file:///srv/templates.git#--upload-pack=touch " + marker,
+	} {
+		if _, cleanup, err := sourcemodule.Resolve(from); err == nil {
+			cleanup()
+			t.Errorf("Resolve(%q) succeeded", from)
+		}
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("git ran a command from the source reference")
+	}
+}
+
+func mustWrite(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+// Package presets implements ports.PresetRepo from the built-in presets.yaml plus presets
+// defined in gocraft settings.
+package presets
+
+import (
+	_ "embed"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nduyhai/gocraft/internal/core/entity"
+	"gopkg.in/yaml.v3"
+)
+
+// SourceBuiltin marks presets shipped with gocraft.
+const SourceBuiltin = "built-in"
+
+//go:embed presets.yaml
+var builtinYAML []byte
+
+// Repo holds presets by name.
+type Repo struct {
+	presets map[string]entity.Preset
+}
+
+// New returns the built-in presets overlaid with extra ones, which replace built-ins of the
+// same name. Extra presets keep their Source.
+func New(extra ...entity.Preset) (*Repo, error) {
+	builtin, err := Parse(builtinYAML, SourceBuiltin)
+	if err != nil {
+		return nil, fmt.Errorf("built-in presets: %w", err)
+	}
+	r := &Repo{presets: map[string]entity.Preset{}}
+	for _, p := range append(builtin, extra...) {
+		r.presets[p.Name] = p
+	}
+	return r, nil
+}
+
+// Parse reads a name => preset YAML mapping, tagging each preset with source.
+func Parse(data []byte, source string) ([]entity.Preset, error) {
+	var m map[string]entity.Preset
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	out := make([]entity.Preset, 0, len(m))
+	for name, p := range m {
+		p.Name, p.Source = name, source
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// List returns the presets sorted by name.
+func (r *Repo) List() []entity.Preset {
+	out := make([]entity.Preset, 0, len(r.presets))
+	for _, p := range r.presets {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Get returns the named preset.
+func (r *Repo) Get(name string) (entity.Preset, error) {
+	p, ok := r.presets[name]
+	if !ok {
+		names := make([]string, 0, len(r.presets))
+		for n := range r.presets {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return entity.Preset{}, fmt.Errorf("unknown preset %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	return p, nil
+}
@@ -0,0 +1,43 @@
+package presets_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/presets"
+	"github.com/nduyhai/gocraft/internal/core/entity"
+)
+
+func TestBuiltinsAndOverrides(t *testing.T) {
+	repo, err := presets.New(
+		entity.Preset{Name: "rest-api", Modules: []string{"http:gin"}, Source: "user"},
+		entity.Preset{Name: "worker", Modules: []string{"feature:makefile"}, Source: "project"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grpc, err := repo.Get("grpc-service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if grpc.Source != presets.SourceBuiltin || !strings.Contains(strings.Join(grpc.Modules, ","), "grpc:server") {
+		t.Errorf("grpc-service = %+v", grpc)
+	}
+	rest, _ := repo.Get("rest-api")
+	if rest.Source != "user" || strings.Join(rest.Modules, ",") != "http:gin" {
+		t.Errorf("rest-api = %+v, want the user preset to replace the built-in", rest)
+	}
+
+	var names []string
+	for _, p := range repo.List() {
+		names = append(names, p.Name)
+	}
+	if got := strings.Join(names, ","); got != "full,grpc-service,rest-api,worker" {
+		t.Errorf("names = %s", got)
+	}
+
+	if _, err := repo.Get("nope"); err == nil || !strings.Contains(err.Error(), "available: full, grpc-service") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -40,24 +40,40 @@ func (r *Registry) List() []ports.Module {
 
 func (r *Registry) Get(name string) (ports.Module, bool) { m, ok := r.byName[name]; return m, ok }
 
-func (r *Registry) Apply(ctx ports.Ctx, names ...string) error {
+// Plan resolves transitive Requires() into a topological order and collects Conflicts().
+func (r *Registry) Plan(names ...string) (ports.Plan, error) {
+	plan := ports.Plan{Requested: append([]string(nil), names...)}
 	if len(names) == 0 {
-		return nil
+		return plan, nil
 	}
 	// Expand requires transitively
 	expanded, err := r.expandRequires(names)
 	if err != nil {
-		return err
-	}
-	// Conflicts detection
-	if err := r.checkConflicts(expanded); err != nil {
-		return err
+		return plan, err
 	}
+	plan.Conflicts = r.conflicts(expanded)
 	// Toposort using DFS with cycle detection
-	ordered, err := r.toposort(expanded)
+	plan.Order, err = r.toposort(expanded)
+	if err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
+
+func (r *Registry) Apply(ctx ports.Ctx, names ...string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	plan, err := r.Plan(names...)
 	if err != nil {
 		return err
 	}
+	if len(plan.Conflicts) > 0 {
+		c := plan.Conflicts[0]
+		return fmt.Errorf("conflict: %s conflicts with %s", c.Module, c.With)
+	}
+	ordered := plan.Order
+	var applied []string
 	// Apply in order
 	for _, name := range ordered {
 		m := r.byName[name]
@@ -67,12 +83,64 @@ func (r *Registry) Apply(ctx ports.Ctx, names ...string) error {
 		if !m.Applies(ctx) {
 			continue
 		}
+		if t, ok := ctx.(ports.ModuleTracker); ok {
+			t.Enter(name)
+		}
+		hp, _ := m.(ports.HookProvider)
+		if err := runApplyHooks(ctx, hp, ports.HookPreApply); err != nil {
+			return fmt.Errorf("apply %s: %w", name, err)
+		}
 		if err := m.Apply(ctx); err != nil {
 			return fmt.Errorf("apply %s: %w", name, err)
 		}
+		if err := runApplyHooks(ctx, hp, ports.HookPostApply); err != nil {
+			return fmt.Errorf("apply %s: %w", name, err)
+		}
+		applied = append(applied, name)
 		// After applying a module, ensure its default config is present.
 		if cfg := ctx.Config(); cfg != nil {
-			_ = cfg.EnsureDefaultsFor(name)
+			if err := cfg.EnsureDefaults(m); err != nil {
+				return fmt.Errorf("apply %s: config: %w", name, err)
+			}
+		}
+	}
+	// Collect post-generate hooks once every module has run, so they see the final tree
+	if c, ok := ctx.(ports.HookCollector); ok {
+		for _, name := range applied {
+			hp, ok := r.byName[name].(ports.HookProvider)
+			if !ok {
+				continue
+			}
+			for _, h := range hp.Hooks(ctx) {
+				if h.Stage == ports.HookPostGenerate {
+					c.Defer(name, h)
+				}
+			}
+		}
+		// The DI editor asks for code generation (wire) once its edits are on disk
+		if hp, ok := ctx.AdaptersModule().(ports.HookProvider); ok {
+			for _, h := range hp.Hooks(ctx) {
+				c.Defer("di", h)
+			}
+		}
+	}
+	return nil
+}
+
+// runApplyHooks runs the module's in-process hooks for stage.
+func runApplyHooks(ctx ports.Ctx, hp ports.HookProvider, stage ports.HookStage) error {
+	if hp == nil {
+		return nil
+	}
+	for _, h := range hp.Hooks(ctx) {
+		if h.Stage != stage {
+			continue
+		}
+		if h.Apply == nil {
+			return fmt.Errorf("%s hook %q: only Go callbacks are supported", stage, h.Name)
+		}
+		if err := h.Apply(ctx); err != nil {
+			return fmt.Errorf("%s hook %q: %w", stage, h.Name, err)
 		}
 	}
 	return nil
@@ -105,19 +173,29 @@ func (r *Registry) expandRequires(names []string) (map[string]struct{}, error) {
 	return seen, nil
 }
 
-func (r *Registry) checkConflicts(set map[string]struct{}) error {
+// conflicts lists declared conflicts between members of set, sorted and without mirrored duplicates.
+func (r *Registry) conflicts(set map[string]struct{}) []ports.Conflict {
+	names := make([]string, 0, len(set))
 	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	seen := make(map[[2]string]bool)
+	var out []ports.Conflict
+	for _, name := range names {
 		m := r.byName[name]
 		if m == nil {
 			continue
 		}
 		for _, c := range m.Conflicts() {
-			if _, ok := set[c]; ok {
-				return fmt.Errorf("conflict: %s conflicts with %s", name, c)
+			if _, ok := set[c]; !ok || seen[[2]string{c, name}] {
+				continue
 			}
+			seen[[2]string{name, c}] = true
+			out = append(out, ports.Conflict{Module: name, With: c})
 		}
 	}
-	return nil
+	return out
 }
 
 func (r *Registry) toposort(set map[string]struct{}) ([]string, error) {
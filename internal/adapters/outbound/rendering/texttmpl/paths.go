@@ -0,0 +1,126 @@
+package texttmpl
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// pathToken matches __<expr>__ segments in template paths. The expression is a value
+// reference with an optional case filter:
+//
+//	__name__                 -> .Name (top-level keys match case-insensitively)
+//	__module__               -> .Module
+//	__values.entity.name__   -> .entity.name ("values." prefix is optional)
+//	__entity|snake__         -> snake-cased .entity (filters: lower, upper, kebab, snake)
+//	__name-kebab__           -> legacy spelling of __name|kebab__
+//
+// Tokens whose value cannot be resolved are left untouched, so names such as
+// __init__.py survive rendering.
+var pathToken = regexp.MustCompile(`__([A-Za-z][A-Za-z0-9_.|\-]*?)__`)
+
+var pathFilters = map[string]func(string) string{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"kebab": toKebab,
+	"snake": toSnake,
+}
+
+// applyPathTokens resolves path tokens from context values and makes sure the result
+// stays inside the project root.
+func applyPathTokens(path string, ctx any) (string, error) {
+	vals, _ := ctx.(map[string]any)
+	var tokErr error
+	out := pathToken.ReplaceAllStringFunc(path, func(tok string) string {
+		v, ok, err := resolvePathToken(strings.Trim(tok, "_"), vals)
+		if err != nil && tokErr == nil {
+			tokErr = fmt.Errorf("template path %q: %w", path, err)
+		}
+		if ok {
+			return v
+		}
+		return tok
+	})
+	if tokErr != nil {
+		return "", tokErr
+	}
+	clean := filepath.Clean(out)
+	if filepath.IsAbs(clean) || filepath.VolumeName(clean) != "" ||
+		clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("template path %q renders to %q outside the project root", path, out)
+	}
+	return clean, nil
+}
+
+// resolvePathToken evaluates a single token expression such as "entity.name|snake".
+// An unknown filter is an error rather than a literal token, as it is a typo in the template.
+func resolvePathToken(expr string, vals map[string]any) (string, bool, error) {
+	ref, filter, hasFilter := strings.Cut(expr, "|")
+	if !hasFilter {
+		// Legacy suffix form: __name-kebab__, __name-snake__
+		for _, f := range []string{"kebab", "snake"} {
+			if base, ok := strings.CutSuffix(ref, "-"+f); ok {
+				ref, filter, hasFilter = base, f, true
+				break
+			}
+		}
+	}
+	fn := pathFilters[filter]
+	if hasFilter && fn == nil {
+		return "", false, fmt.Errorf("unknown filter %q in __%s__", filter, expr)
+	}
+	v, ok := lookupValue(vals, strings.TrimPrefix(ref, "values."))
+	if !ok {
+		return "", false, nil
+	}
+	if hasFilter {
+		v = fn(v)
+	}
+	return v, true, nil
+}
+
+// lookupValue resolves a dot path in vals to a scalar rendered as a string.
+// Keys match exactly; a top-level key that does not falls back to a case-insensitive match.
+func lookupValue(vals map[string]any, ref string) (string, bool) {
+	var cur any = vals
+	for i, key := range strings.Split(ref, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok || key == "" {
+			return "", false
+		}
+		v, ok := m[key]
+		if !ok && i == 0 {
+			v, ok = lookupFold(m, key)
+		}
+		if !ok {
+			return "", false
+		}
+		cur = v
+	}
+	switch v := cur.(type) {
+	case nil, map[string]any, []any:
+		return "", false
+	case string:
+		return v, v != ""
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// lookupFold returns the value of the first key, in sorted order, equal to key under case
+// folding, so that "name" resolves the same way whether vals holds "Name", "NAME" or both.
+func lookupFold(m map[string]any, key string) (any, bool) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		if strings.EqualFold(k, key) {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, false
+	}
+	sort.Strings(keys)
+	return m[keys[0]], true
+}
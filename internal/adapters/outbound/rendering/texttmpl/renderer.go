@@ -2,7 +2,10 @@ package texttmpl
 
 import (
 	"bytes"
+	"fmt"
+	"io/fs"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -10,28 +13,137 @@ import (
 	"github.com/nduyhai/gocraft/internal/core/ports"
 )
 
+const (
+	// defaultMode is used when neither the source nor a directive specifies a mode.
+	defaultMode fs.FileMode = 0o644
+
+	// directivePrefix opens an optional first-line directive in .tmpl files, e.g.
+	//
+	//	{{/* gocraft: verbatim mode=0755 */}}
+	//
+	// It is a template comment, so templates stay valid for plain text/template.
+	directivePrefix = "{{/* gocraft:"
+	directiveSuffix = "*/}}"
+
+	// rawOpen and rawClose delimit sections emitted literally, so Helm charts or
+	// GitHub Actions snippets containing "{{" can live inside a rendered template.
+	rawOpen  = "{{/* raw */}}"
+	rawClose = "{{/* endraw */}}"
+)
+
 type Renderer struct{}
 
 func New() *Renderer { return &Renderer{} }
 
+// Render renders .tmpl files and copies every other file verbatim; config defaults and schema
+// templates (ports.KindDefaults, ports.KindSchema) are left to the ConfigEditor.
+// The output mode comes from a directive, then the source file, then defaultMode. A template
+// that renders to blank text produces no file (unless it is blank itself), so variants can be
+// wrapped in {{ if }}.
 func (Renderer) Render(tpl ports.Template, ctx any) ([]entity.File, error) {
 	var out []entity.File
 	for _, f := range tpl.Files {
-		// Render file content
-		content, err := renderString(f.Content, ctx)
+		if f.Kind != ports.KindFile {
+			continue
+		}
+		// Path token rewrites and .tmpl stripping
+		path, err := applyPathTokens(filepath.FromSlash(f.Path), ctx)
 		if err != nil {
 			return nil, err
 		}
-		// Path token rewrites and .tmpl stripping
-		path := applyPathTokens(filepath.FromSlash(f.Path), ctx)
+		mode := f.Mode.Perm()
+		var content []byte
 		if strings.HasSuffix(path, ".tmpl") {
 			path = strings.TrimSuffix(path, ".tmpl")
+			body, d, err := parseDirective(f.Content)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", f.Path, err)
+			}
+			if d.mode != 0 {
+				mode = d.mode
+			}
+			if d.verbatim {
+				content = []byte(body)
+			} else {
+				rendered, err := renderString(body, ctx)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", f.Path, err)
+				}
+				if strings.TrimSpace(rendered) == "" && strings.TrimSpace(body) != "" {
+					continue
+				}
+				content = []byte(rendered)
+			}
+		} else {
+			content = []byte(f.Content)
+		}
+		if mode == 0 {
+			mode = defaultMode
 		}
-		out = append(out, entity.File{Path: path, Content: []byte(content), Mode: 0o644})
+		out = append(out, entity.File{Path: path, Content: content, Mode: mode})
 	}
 	return out, nil
 }
 
+// directive holds the options parsed from a leading gocraft directive.
+type directive struct {
+	verbatim bool
+	mode     fs.FileMode
+}
+
+// parseDirective strips an optional first-line directive and returns the remaining body.
+func parseDirective(content string) (string, directive, error) {
+	var d directive
+	if !strings.HasPrefix(content, directivePrefix) {
+		return content, d, nil
+	}
+	line, rest, _ := strings.Cut(content, "\n")
+	line = strings.TrimSuffix(line, "\r")
+	if !strings.HasSuffix(line, directiveSuffix) {
+		return "", d, fmt.Errorf("unterminated gocraft directive")
+	}
+	opts := strings.TrimSuffix(strings.TrimPrefix(line, directivePrefix), directiveSuffix)
+	for _, opt := range strings.Fields(opts) {
+		key, val, _ := strings.Cut(opt, "=")
+		switch key {
+		case "verbatim":
+			d.verbatim = true
+		case "mode":
+			m, err := strconv.ParseUint(val, 8, 32)
+			if err != nil || m > 0o777 {
+				return "", d, fmt.Errorf("invalid mode %q in gocraft directive", val)
+			}
+			d.mode = fs.FileMode(m)
+		default:
+			return "", d, fmt.Errorf("unknown gocraft directive option %q", key)
+		}
+	}
+	return rest, d, nil
+}
+
+// protectRaw turns every raw section into a quoted string action so its content
+// is emitted as-is by text/template.
+func protectRaw(tmpl string) (string, error) {
+	var b strings.Builder
+	for {
+		i := strings.Index(tmpl, rawOpen)
+		if i < 0 {
+			b.WriteString(tmpl)
+			return b.String(), nil
+		}
+		b.WriteString(tmpl[:i])
+		rest := tmpl[i+len(rawOpen):]
+		j := strings.Index(rest, rawClose)
+		if j < 0 {
+			return "", fmt.Errorf("unterminated raw section")
+		}
+		b.WriteString("{{")
+		b.WriteString(strconv.Quote(rest[:j]))
+		b.WriteString("}}")
+		tmpl = rest[j+len(rawClose):]
+	}
+}
+
 func renderString(tmpl string, data any) (string, error) {
 	funcs := template.FuncMap{
 		"lower": strings.ToLower,
@@ -39,6 +151,10 @@ func renderString(tmpl string, data any) (string, error) {
 		"kebab": toKebab,
 		"snake": toSnake,
 	}
+	tmpl, err := protectRaw(tmpl)
+	if err != nil {
+		return "", err
+	}
 	t, err := template.New("").Funcs(funcs).Parse(tmpl)
 	if err != nil {
 		return "", err
@@ -50,37 +166,6 @@ func renderString(tmpl string, data any) (string, error) {
 	return strings.ReplaceAll(buf.String(), "\r\n", "\n"), nil
 }
 
-// applyPathTokens replaces path tokens like __name__ and __module__ using context values.
-func applyPathTokens(path string, ctx any) string {
-	name := getCtxString(ctx, "Name")
-	module := getCtxString(ctx, "Module")
-	if name != "" {
-		path = strings.ReplaceAll(path, "__name__", name)
-	}
-	if module != "" {
-		path = strings.ReplaceAll(path, "__module__", module)
-	}
-	// Also support kebab/snake variants if template authors use them in paths
-	if name != "" {
-		path = strings.ReplaceAll(path, "__name-kebab__", toKebab(name))
-		path = strings.ReplaceAll(path, "__name-snake__", toSnake(name))
-	}
-	return path
-}
-
-func getCtxString(ctx any, key string) string {
-	m, ok := ctx.(map[string]any)
-	if !ok {
-		return ""
-	}
-	if v, ok := m[key]; ok {
-		if s, ok := v.(string); ok {
-			return s
-		}
-	}
-	return ""
-}
-
 func toKebab(s string) string {
 	parts := splitWords(s)
 	for i := range parts {
@@ -0,0 +1,140 @@
+package texttmpl_test
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/rendering/texttmpl"
+	"github.com/nduyhai/gocraft/internal/core/ports"
+)
+
+func TestRenderer_VerbatimModesAndRaw(t *testing.T) {
+	binary := string([]byte{0x89, 'P', 'N', 'G', 0x00, '{', '{'})
+	tpl := ports.Template{Name: "test", Files: []ports.TmplFile{
+		{Path: "main.go.tmpl", Content: "package {{ .Name }}\n"},
+		{Path: "assets/favicon.png", Content: binary},
+		{Path: "tools/tool.jar", Content: "PK{{", Mode: 0o755},
+		{Path: "scripts/run.sh.tmpl", Content: "{{/* gocraft: mode=0755 */}}\n#!/bin/sh\necho {{ .Name }}\n"},
+		{Path: "charts/values.yaml.tmpl", Content: "{{/* gocraft: verbatim */}}\nimage: {{ .Values.image }}\n"},
+		{Path: ".github/workflows/ci.yml.tmpl", Content: "name: {{ .Name }}\nrun: ${{/* raw */}}{{ github.sha }}{{/* endraw */}}\n"},
+		{Path: "wire.go.tmpl", Content: "{{ if eq .Name \"wire\" }}package di\n{{ end }}\n"},
+	}}
+
+	files, err := texttmpl.New().Render(tpl, map[string]any{"Name": "app"})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	got := make(map[string]struct {
+		content string
+		mode    fs.FileMode
+	})
+	for _, f := range files {
+		got[filepath.ToSlash(f.Path)] = struct {
+			content string
+			mode    fs.FileMode
+		}{string(f.Content), f.Mode}
+	}
+
+	cases := []struct {
+		path    string
+		content string
+		mode    fs.FileMode
+	}{
+		{"main.go", "package app\n", 0o644},
+		{"assets/favicon.png", binary, 0o644},
+		{"tools/tool.jar", "PK{{", 0o755},
+		{"scripts/run.sh", "#!/bin/sh\necho app\n", 0o755},
+		{"charts/values.yaml", "image: {{ .Values.image }}\n", 0o644},
+		{".github/workflows/ci.yml", "name: app\nrun: ${{ github.sha }}\n", 0o644},
+	}
+	if _, ok := got["wire.go"]; ok {
+		t.Error("blank template rendered to a file")
+	}
+	for _, c := range cases {
+		f, ok := got[c.path]
+		if !ok {
+			t.Fatalf("missing %s; got %v", c.path, got)
+		}
+		if f.content != c.content {
+			t.Errorf("%s content = %q, want %q", c.path, f.content, c.content)
+		}
+		if f.mode != c.mode {
+			t.Errorf("%s mode = %o, want %o", c.path, f.mode, c.mode)
+		}
+	}
+}
+
+func TestRenderer_SkipsConfigDefaultsAndSchema(t *testing.T) {
+	tpl := ports.Template{Files: []ports.TmplFile{
+		{Path: "config/defaults.yml.tmpl", Content: "a: 1", Kind: ports.KindDefaults},
+		{Path: "config/schema.json.tmpl", Content: "{}", Kind: ports.KindSchema},
+		{Path: "main.go.tmpl", Content: "package main"},
+	}}
+	files, err := texttmpl.New().Render(tpl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Path != "main.go" {
+		t.Fatalf("rendered %v, want main.go only", files)
+	}
+}
+
+func TestRenderer_InvalidDirective(t *testing.T) {
+	tpl := ports.Template{Files: []ports.TmplFile{
+		{Path: "a.tmpl", Content: "{{/* gocraft: bogus */}}\nx"},
+	}}
+	if _, err := texttmpl.New().Render(tpl, nil); err == nil {
+		t.Fatal("expected error for unknown directive option")
+	}
+}
+
+func TestRenderer_PathTokens(t *testing.T) {
+	vals := map[string]any{
+		"Name":   "MyApp",
+		"Module": "example.com/myapp",
+		"entity": map[string]any{"name": "OrderItem"},
+		"port":   8080,
+	}
+	cases := map[string]string{
+		"cmd/__name__/main.go.tmpl":                        "cmd/MyApp/main.go",
+		"deploy/__name-kebab__.yaml.tmpl":                  "deploy/my-app.yaml",
+		"deploy/__name|snake__.yaml.tmpl":                  "deploy/my_app.yaml",
+		"internal/core/usecase/__entity.name|snake__/a.go": "internal/core/usecase/order_item/a.go",
+		"internal/__values.entity.name|kebab__/b.go":       "internal/order-item/b.go",
+		"ports/__port__.txt":                               "ports/8080.txt",
+		"scripts/__init__.py":                              "scripts/__init__.py",
+		"docs/__entity.missing|snake__/README.md":          "docs/__entity.missing|snake__/README.md",
+		"docs/__entity.NAME__/README.md":                   "docs/__entity.NAME__/README.md",
+	}
+	for in, want := range cases {
+		tpl := ports.Template{Files: []ports.TmplFile{{Path: in}}}
+		files, err := texttmpl.New().Render(tpl, vals)
+		if err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+		if got := filepath.ToSlash(files[0].Path); got != want {
+			t.Errorf("%s -> %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestRenderer_PathTokensRejectUnknownFilter(t *testing.T) {
+	for _, path := range []string{"__entity|snkae__/c.go", "__missing|snkae__/c.go"} {
+		tpl := ports.Template{Files: []ports.TmplFile{{Path: path}}}
+		_, err := texttmpl.New().Render(tpl, map[string]any{"entity": "OrderItem"})
+		if err == nil || !strings.Contains(err.Error(), "snkae") {
+			t.Errorf("%s: err = %v, want unknown filter error", path, err)
+		}
+	}
+}
+
+func TestRenderer_PathTokensRejectEscapes(t *testing.T) {
+	for _, v := range []string{"../../etc", "/abs", ".."} {
+		tpl := ports.Template{Files: []ports.TmplFile{{Path: "__dir__/x.txt"}}}
+		if _, err := texttmpl.New().Render(tpl, map[string]any{"dir": v}); err == nil {
+			t.Errorf("dir=%q: expected error for path escaping the project root", v)
+		}
+	}
+}
@@ -3,37 +3,56 @@ package embed_repo
 import (
 	"fmt"
 	"io/fs"
+	"os"
+	"path/filepath"
 	"strings"
 
-	ginmodule "github.com/nduyhai/gocraft/internal/adapters/outbound/modules/http/gin"
-	base "github.com/nduyhai/gocraft/internal/adapters/outbound/modules/platform/base"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/templates/overlay"
 	"github.com/nduyhai/gocraft/internal/core/ports"
 )
 
-// New returns an embedded template repository with available templates.
-func New() ports.TemplateRepo { return &repo{names: []string{"basic", "http:gin"}} }
+// DefaultsPath is the module-relative template holding a module's config defaults.
+// It and its per-profile variants (config/defaults.<profile>.yml.tmpl) are merged into the
+// project config by the ConfigEditor and never rendered as files, like ports.SchemaTemplate.
+const DefaultsPath = ports.DefaultsTemplate
 
-type repo struct{ names []string }
+// SourceBuiltin marks template files that come from a module's embedded templates.
+const SourceBuiltin = "builtin"
 
-func (r *repo) Names() []string { return append([]string(nil), r.names...) }
+// New returns a template repository covering every registered module that ships
+// templates (ports.TemplateSource). Files found in override directories under the
+// given search roots shadow the built-in ones (see overlay.New).
+func New(reg ports.Registry, searchRoots []string) ports.TemplateRepo {
+	return &repo{reg: reg, roots: searchRoots}
+}
 
-func (r *repo) Load(name string) (ports.Template, error) {
-	var (
-		fsys fs.FS
-	)
-	switch name {
-	case "basic":
-		fsys = base.TemplatesFS
-	case "http:gin":
-		fsys = ginmodule.TemplatesFS
-	default:
-		return ports.Template{}, fmt.Errorf("unknown template: %s", name)
+type repo struct {
+	reg   ports.Registry
+	roots []string
+}
+
+// Names lists the modules that provide templates, in registration order.
+func (r *repo) Names() []string {
+	var names []string
+	for _, m := range r.reg.List() {
+		if _, ok := m.(ports.TemplateSource); ok {
+			names = append(names, m.Name())
+		}
 	}
-	// We want paths relative to the root of the templates dir
-	sub, err := fs.Sub(fsys, "templates")
+	return names
+}
+
+// Load walks the module's template tree, with overrides applied, and returns its files,
+// including the config defaults and schema (see ports.TemplateKind).
+func (r *repo) Load(name string) (ports.Template, error) {
+	sub, err := r.FS(name)
 	if err != nil {
 		return ports.Template{}, err
 	}
+	if sub == nil {
+		return ports.Template{Name: name}, nil
+	}
+	dirs := overlay.Dirs(name, r.roots)
 	var files []ports.TmplFile
 	err = fs.WalkDir(sub, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -42,16 +61,50 @@ func (r *repo) Load(name string) (ports.Template, error) {
 		if d.IsDir() {
 			return nil
 		}
+		clean := strings.TrimPrefix(path, "./")
 		b, err := fs.ReadFile(sub, path)
 		if err != nil {
 			return err
 		}
-		clean := strings.TrimPrefix(path, "./")
-		files = append(files, ports.TmplFile{Path: clean, Content: string(b)})
+		// Embedded files carry no meaningful mode; keep only an executable bit set on disk.
+		var mode fs.FileMode
+		if info, err := d.Info(); err == nil && info.Mode()&0o111 != 0 {
+			mode = info.Mode().Perm()
+		}
+		files = append(files, ports.TmplFile{
+			Path:    clean,
+			Content: string(b),
+			Mode:    mode,
+			Source:  sourceOf(dirs, clean),
+			Kind:    ports.TemplateKind(clean),
+		})
 		return nil
 	})
 	if err != nil {
-		return ports.Template{}, err
+		return ports.Template{}, fmt.Errorf("walk %s: %w", name, err)
 	}
 	return ports.Template{Name: name, Files: files}, nil
 }
+
+// FS returns the module's template tree with overrides applied (see overlay.New).
+func (r *repo) FS(name string) (fs.FS, error) {
+	m, ok := r.reg.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown template: %s", name)
+	}
+	src, ok := m.(ports.TemplateSource)
+	if !ok {
+		return nil, nil
+	}
+	return overlay.New(src.Templates(), name, r.roots), nil
+}
+
+// sourceOf returns the override directory serving path, or SourceBuiltin.
+func sourceOf(dirs []string, path string) string {
+	for _, d := range dirs {
+		if _, err := os.Stat(filepath.Join(d, filepath.FromSlash(path))); err == nil {
+			return d
+		}
+	}
+	return SourceBuiltin
+}
@@ -0,0 +1,80 @@
+package embed_repo_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/modules/register"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/registry/embed_registry"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/templates/embed_repo"
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/templates/overlay"
+	"github.com/nduyhai/gocraft/internal/core/ports"
+)
+
+func TestRepo_LoadWithOverrides(t *testing.T) {
+	reg := embed_registry.New()
+	register.Builtins(reg)
+	root := t.TempDir()
+	dir := filepath.Join(root, overlay.ModuleDir("db:gorm"))
+	mustWrite(t, filepath.Join(dir, "internal", "platform", "db", "gorm", "module.go.tmpl"), "package gorm // custom\n")
+	repo := embed_repo.New(reg, []string{root})
+
+	if names := repo.Names(); !slices.Contains(names, "db:gorm") || !slices.Contains(names, "platform:base") {
+		t.Fatalf("Names = %v", names)
+	}
+
+	tpl, err := repo.Load("db:gorm")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	got := map[string]ports.TmplFile{}
+	for _, f := range tpl.Files {
+		got[f.Path] = f
+	}
+	for path, want := range map[string]struct{ kind, source string }{
+		"internal/platform/db/gorm/module.go.tmpl": {ports.KindFile, dir},
+		"config/defaults.yml.tmpl":                 {ports.KindDefaults, embed_repo.SourceBuiltin},
+		"config/defaults.local.yml.tmpl":           {ports.KindDefaults, embed_repo.SourceBuiltin},
+		"config/schema.json.tmpl":                  {ports.KindSchema, embed_repo.SourceBuiltin},
+	} {
+		f, ok := got[path]
+		if !ok {
+			t.Errorf("%s not loaded", path)
+			continue
+		}
+		if f.Kind != want.kind || f.Source != want.source {
+			t.Errorf("%s: kind %q from %q, want %q from %q", path, f.Kind, f.Source, want.kind, want.source)
+		}
+	}
+	if c := got["internal/platform/db/gorm/module.go.tmpl"].Content; c != "package gorm // custom\n" {
+		t.Errorf("override not loaded: %q", c)
+	}
+
+	fsys, err := repo.FS("db:gorm")
+	if err != nil {
+		t.Fatalf("FS: %v", err)
+	}
+	if b, err := fs.ReadFile(fsys, "internal/platform/db/gorm/module.go.tmpl"); err != nil || string(b) != "package gorm // custom\n" {
+		t.Errorf("FS serves %q, %v; want the override", b, err)
+	}
+	if _, err := fs.Stat(fsys, ports.DefaultsTemplate); err != nil {
+		t.Errorf("FS lacks the defaults: %v", err)
+	}
+
+	if _, err := repo.Load("nope:missing"); err == nil {
+		t.Error("Load of an unknown module succeeded")
+	}
+}
+
+func mustWrite(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
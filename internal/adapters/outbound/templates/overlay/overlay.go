@@ -0,0 +1,166 @@
+package overlay
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// FS is a read-only union of file systems. Earlier layers shadow later ones:
+// a file present in several layers is served from the first that has it, and
+// directory listings are merged across all layers.
+type FS struct {
+	layers []fs.FS
+}
+
+// New returns the built-in template tree of a module overlaid by any override
+// directories found under the given search roots (highest priority first).
+// For a root R the override directory is R/<module>, e.g.
+// .gocraft/templates/feature:dockerfile/Dockerfile.tmpl.
+func New(builtin fs.FS, module string, roots []string) fs.FS {
+	var layers []fs.FS
+	for _, dir := range Dirs(module, roots) {
+		layers = append(layers, os.DirFS(dir))
+	}
+	if len(layers) == 0 {
+		return builtin
+	}
+	return Union(append(layers, builtin)...)
+}
+
+// Union returns the union of the given layers, the first one shadowing the others.
+func Union(layers ...fs.FS) fs.FS { return &FS{layers: layers} }
+
+// Dirs returns the existing override directories for a module under roots.
+func Dirs(module string, roots []string) []string {
+	var out []string
+	for _, r := range roots {
+		dir := filepath.Join(r, ModuleDir(module))
+		if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
+			out = append(out, dir)
+		}
+	}
+	return out
+}
+
+// ModuleDir maps a module name to its override directory name. Module names are
+// used as-is, except on Windows where ':' is not allowed in file names.
+func ModuleDir(module string) string {
+	if runtime.GOOS == "windows" {
+		return strings.ReplaceAll(module, ":", "-")
+	}
+	return module
+}
+
+// Open opens the named file from the first layer that has it.
+func (u *FS) Open(name string) (fs.File, error) {
+	var firstErr error
+	for _, l := range u.layers {
+		f, err := l.Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// ReadFile reads the named file from the first layer that has it.
+func (u *FS) ReadFile(name string) ([]byte, error) {
+	var firstErr error
+	for _, l := range u.layers {
+		b, err := fs.ReadFile(l, name)
+		if err == nil {
+			return b, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// ReadDir merges the entries of the named directory across all layers.
+func (u *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	seen := make(map[string]bool)
+	var (
+		out      []fs.DirEntry
+		found    bool
+		firstErr error
+	)
+	for _, l := range u.layers {
+		entries, err := fs.ReadDir(l, name)
+		if err != nil {
+			if firstErr == nil && !errors.Is(err, fs.ErrNotExist) {
+				firstErr = err
+			}
+			continue
+		}
+		found = true
+		for _, e := range entries {
+			if seen[e.Name()] {
+				continue
+			}
+			seen[e.Name()] = true
+			out = append(out, e)
+		}
+	}
+	if !found {
+		if firstErr != nil {
+			return nil, firstErr
+		}
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out, nil
+}
+
+var (
+	_ fs.ReadDirFS  = (*FS)(nil)
+	_ fs.ReadFileFS = (*FS)(nil)
+)
+
+// Only restricts fsys to the directory dir: any other path does not exist, except the
+// directories leading to dir, which list nothing else.
+func Only(fsys fs.FS, dir string) fs.FS { return &only{fsys: fsys, dir: dir} }
+
+type only struct {
+	fsys fs.FS
+	dir  string
+}
+
+func (o *only) visible(name string) bool {
+	return name == "." || name == o.dir || strings.HasPrefix(name, o.dir+"/") ||
+		strings.HasPrefix(o.dir, name+"/")
+}
+
+func (o *only) Open(name string) (fs.File, error) {
+	if !o.visible(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return o.fsys.Open(name)
+}
+
+func (o *only) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !o.visible(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	entries, err := fs.ReadDir(o.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	out := entries[:0:0]
+	for _, e := range entries {
+		if o.visible(path.Join(name, e.Name())) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
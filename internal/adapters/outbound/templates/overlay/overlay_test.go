@@ -0,0 +1,108 @@
+package overlay_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/templates/overlay"
+)
+
+func TestNew_OverridesShadowBuiltin(t *testing.T) {
+	builtin := fstest.MapFS{
+		"Dockerfile.tmpl":      {Data: []byte("FROM golang:1.24-alpine")},
+		"config/defaults.tmpl": {Data: []byte("builtin")},
+	}
+	project := t.TempDir()
+	user := t.TempDir()
+	mustWrite(t, filepath.Join(project, "feature:dockerfile", "Dockerfile.tmpl"), "FROM project")
+	mustWrite(t, filepath.Join(user, "feature:dockerfile", "Dockerfile.tmpl"), "FROM user")
+	mustWrite(t, filepath.Join(user, "feature:dockerfile", "extra.txt"), "extra")
+
+	fsys := overlay.New(builtin, "feature:dockerfile", []string{project, user})
+
+	for path, want := range map[string]string{
+		"Dockerfile.tmpl":      "FROM project",
+		"extra.txt":            "extra",
+		"config/defaults.tmpl": "builtin",
+	} {
+		b, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			t.Fatalf("read %s: %v", path, err)
+		}
+		if string(b) != want {
+			t.Errorf("%s = %q, want %q", path, b, want)
+		}
+	}
+
+	var walked []string
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			walked = append(walked, path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walk: %v", err)
+	}
+	if len(walked) != 3 {
+		t.Fatalf("walked %v, want 3 unique files", walked)
+	}
+}
+
+func TestNew_NoOverridesReturnsBuiltin(t *testing.T) {
+	builtin := fstest.MapFS{"a.tmpl": {Data: []byte("a")}}
+	if got := overlay.New(builtin, "http:gin", []string{t.TempDir()}); got == nil {
+		t.Fatal("nil fs")
+	} else if _, ok := got.(fstest.MapFS); !ok {
+		t.Fatalf("expected builtin fs to be returned unchanged, got %T", got)
+	}
+}
+
+func TestUnion_OnlySharesASubtree(t *testing.T) {
+	own := fstest.MapFS{"go.mod.tmpl": {Data: []byte("own")}}
+	shared := fstest.MapFS{
+		"go.mod.tmpl":          {Data: []byte("shared")},
+		"config/config.yml":    {Data: []byte("app: {}")},
+		"internal/app.go.tmpl": {Data: []byte("package app")},
+	}
+	fsys := overlay.Union(own, overlay.Only(shared, "config"))
+
+	var walked []string
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			walked = append(walked, path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walk: %v", err)
+	}
+	if len(walked) != 2 || walked[0] != "config/config.yml" || walked[1] != "go.mod.tmpl" {
+		t.Fatalf("walked %v, want [config/config.yml go.mod.tmpl]", walked)
+	}
+	if b, _ := fs.ReadFile(fsys, "go.mod.tmpl"); string(b) != "own" {
+		t.Errorf("go.mod.tmpl = %q, want own", b)
+	}
+	if _, err := fs.ReadFile(fsys, "internal/app.go.tmpl"); err == nil {
+		t.Error("internal/app.go.tmpl is outside the shared subtree but readable")
+	}
+}
+
+func mustWrite(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package verify
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Origins returns the modules that generated a project-relative file.
+type Origins func(file string) []string
+
+// Verifier compiles and vets a generated project and attributes diagnostics to the
+// modules whose templates produced the offending files.
+type Verifier struct {
+	origins Origins
+}
+
+func New(origins Origins) *Verifier { return &Verifier{origins: origins} }
+
+// diagnostic matches compiler and vet positions such as "./internal/x.go:12:3: msg",
+// optionally prefixed with "vet: ".
+var diagnostic = regexp.MustCompile(`^(?:vet: )?(?:\./)?([^\s:]+\.go):(\d+)(?::\d+)?: `)
+
+// Build runs `go build ./...` in dir.
+func (v *Verifier) Build(dir string) (string, error) { return v.run(dir, "build", "./...") }
+
+// Vet runs `go vet ./...` in dir.
+func (v *Verifier) Vet(dir string) (string, error) { return v.run(dir, "vet", "./...") }
+
+// run executes the go command offline and, on failure, annotates each diagnostic with the
+// modules that generated its file.
+func (v *Verifier) run(dir string, args ...string) (string, error) {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	// Requirements are tidied beforehand, so -mod=readonly applies whatever the user's GOFLAGS
+	// say; it is also the only mode allowed when the project is part of a go.work workspace.
+	cmd.Env = append(os.Environ(), "GOPROXY=off", "GOFLAGS="+readonlyFlags(os.Getenv("GOFLAGS")))
+	var buf bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &buf, &buf
+	err := cmd.Run()
+	out := strings.TrimSpace(buf.String())
+	if err == nil {
+		return out, nil
+	}
+	if strings.Contains(out, "GOPROXY=off") {
+		return out, fmt.Errorf("cannot verify offline: dependencies are not in the module cache (run with --tidy while online)")
+	}
+
+	culprits := map[string]bool{}
+	lines := strings.Split(out, "\n")
+	problems := 0
+	for i, line := range lines {
+		m := diagnostic.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		problems++
+		mods := v.origins(filepath.FromSlash(m[1]))
+		if len(mods) == 0 {
+			continue
+		}
+		for _, mod := range mods {
+			culprits[mod] = true
+		}
+		lines[i] = line + "  [" + strings.Join(mods, ", ") + "]"
+	}
+	out = strings.Join(lines, "\n")
+	if len(culprits) == 0 {
+		return out, fmt.Errorf("go %s failed: %w", args[0], err)
+	}
+	names := make([]string, 0, len(culprits))
+	for m := range culprits {
+		names = append(names, m)
+	}
+	sort.Strings(names)
+	return out, fmt.Errorf("%d problem(s) in files generated by %s", problems, strings.Join(names, ", "))
+}
+
+// readonlyFlags returns flags (a GOFLAGS value) with any -mod setting replaced by -mod=readonly.
+func readonlyFlags(flags string) string {
+	out := []string{"-mod=readonly"}
+	for _, f := range strings.Fields(flags) {
+		if name, _, _ := strings.Cut(strings.TrimLeft(f, "-"), "="); name != "mod" {
+			out = append(out, f)
+		}
+	}
+	return strings.Join(out, " ")
+}
@@ -0,0 +1,61 @@
+package verify_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nduyhai/gocraft/internal/adapters/outbound/verify"
+)
+
+func TestVerifier_AttributesFailures(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":            "module example.com/app\n\ngo 1.22\n",
+		"main.go":           "package main\n\nfunc main() {}\n",
+		"internal/db/db.go": "package db\n\nvar broken int = \"x\"\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	origins := map[string][]string{filepath.Join("internal", "db", "db.go"): {"db:gorm"}}
+	v := verify.New(func(file string) []string { return origins[file] })
+
+	out, err := v.Build(dir)
+	if err == nil {
+		t.Fatalf("expected build failure, output:\n%s", out)
+	}
+	if !strings.Contains(err.Error(), "db:gorm") || !strings.Contains(out, "internal/db/db.go:3") || !strings.Contains(out, "[db:gorm]") {
+		t.Fatalf("err = %v, output:\n%s", err, out)
+	}
+}
+
+func TestVerifier_WorkspaceIgnoresModFlag(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.work":     "go 1.22\n\nuse ./app\n",
+		"app/go.mod":  "module example.com/app\n\ngo 1.22\n",
+		"app/main.go": "package main\n\nfunc main() {}\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("GOFLAGS", "-mod=mod")
+	t.Setenv("GOWORK", "")
+	if out, err := verify.New(nil).Build(filepath.Join(dir, "app")); err != nil {
+		t.Fatalf("Build: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,10 @@
+package entity
+
+// Preset bundles modules and template values under one name, e.g. rest-api.
+type Preset struct {
+	Name    string         `yaml:"-"`
+	Summary string         `yaml:"summary,omitempty"`
+	Modules []string       `yaml:"modules,omitempty"`
+	Values  map[string]any `yaml:"values,omitempty"`
+	Source  string         `yaml:"-"` // built-in, user or project
+}
@@ -1,7 +1,45 @@
 package ports
 
+import "fmt"
+
+// Config file formats, chosen with the config.format value.
+const (
+	ConfigYAML = "yaml" // config/config.yml (default)
+	ConfigTOML = "toml" // config/config.toml
+	ConfigJSON = "json" // config/config.json
+	ConfigEnv  = "env"  // no config file: environment only, documented in .env.example
+)
+
+// ConfigFormat returns the config.format value, ConfigYAML when unset.
+func ConfigFormat(values map[string]any) (string, error) {
+	c, _ := values["config"].(map[string]any)
+	f, _ := c["format"].(string)
+	switch f {
+	case "", "yml":
+		return ConfigYAML, nil
+	case ConfigYAML, ConfigTOML, ConfigJSON, ConfigEnv:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown config.format %q (use yaml, toml, json or env)", f)
+}
+
+// ConfigEditor edits the project's config files in whichever format the project uses, which
+// implementations detect from the files present.
 type ConfigEditor interface {
-	// EnsureDefaultsFor updates config/config.yml to include default properties for the given module.
+	// EnsureDefaults updates config/config.yml to include the module's default properties: its
+	// DefaultsTemplate as written when it ships one (TemplateSource), otherwise Defaults().
 	// It must be idempotent and tolerant to missing files; when the config file is absent, it should create it.
-	EnsureDefaultsFor(module string) error
+	// Existing keys, comments and formatting are kept.
+	EnsureDefaults(m Module) error
+	// Get returns the scalar at a dot-separated key such as gorm.driver.
+	Get(key string) (string, bool)
+	// Set sets the scalar at a dot-separated key, adding it when missing and leaving the rest of
+	// the file untouched.
+	Set(key string, value any) error
+	// Profile returns the editor for the config.<name>.yml overlay (local, staging, prod).
+	// Projects without that overlay keep everything in config.yml.
+	Profile(name string) ConfigEditor
+	// Convert rewrites the project's YAML config files in format (one of the Config* constants);
+	// platform modules call it after rendering their YAML templates.
+	Convert(format string) error
 }
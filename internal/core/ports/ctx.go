@@ -8,7 +8,10 @@ type Ctx interface {
 	ProjectRoot() string
 
 	FS() FSWriter
+	// Files gives read/write access to project files; adapters must not use the os package directly.
+	Files() FileSystem
 	Renderer() Renderer
+	Templates() TemplateRepo
 
 	GoMod() GoModEditor
 	AdaptersModule() DependencyInjectionEditor
@@ -1,5 +1,78 @@
 package ports
 
+import "fmt"
+
+// DI styles of a generated project, chosen with the di value.
+const (
+	DIFx     = "fx"     // Uber Fx: modules are fx.Options listed by di.Root() (default)
+	DIWire   = "wire"   // Google Wire: modules are provider sets listed by di.Initialize()
+	DIManual = "manual" // plain constructors: modules are Setup functions called by di.Build()
+)
+
+// DIMode returns the di value, DIFx when unset.
+func DIMode(values map[string]any) (string, error) {
+	m, _ := values["di"].(string)
+	switch m {
+	case "":
+		return DIFx, nil
+	case DIFx, DIWire, DIManual:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown di %q (use fx, wire or manual)", m)
+}
+
+// DIKind is how an entry is wired into the DI root.
+type DIKind string
+
+const (
+	DIOption   DIKind = "option"   // Expr is an fx.Option used as is, e.g. httpchi.Module()
+	DIProvide  DIKind = "provide"  // fx.Provide(Expr)
+	DIInvoke   DIKind = "invoke"   // fx.Invoke(Expr)
+	DIDecorate DIKind = "decorate" // fx.Decorate(Expr)
+)
+
+// DIImport is a package an entry's expressions refer to by Alias.
+type DIImport struct {
+	Alias string
+	Path  string
+}
+
+// DIEntry is one registration in the DI root.
+type DIEntry struct {
+	Kind    DIKind // DIOption when empty
+	Imports []DIImport
+	// Expr is the option, or the constructor, function or decorator the kind wraps.
+	Expr string
+	// As also provides a DIProvide result as these interfaces (fx.As), e.g. "ports.UserRepo".
+	As []string
+	// Group adds a DIProvide result to a value group (fx.ResultTags(`group:"..."`)).
+	Group string
+	// Into names an option function of the DI file, such as adapters, that the entry goes into
+	// instead of the root's options; the function is created, and added to the root, when missing.
+	Into string
+	// File is the Go file, relative to the project root, the entry goes into instead of the DI
+	// root (internal/platform/di/root.go), e.g. internal/adapters/inbound/module.go. A missing
+	// file is created with an empty Func, its package named after the directory.
+	File string
+	// Func is the function of File whose returned fx.Options(...) gets the entry: Root in the DI
+	// root, Module elsewhere by default. It is added to File when missing.
+	Func string
+}
+
+// DependencyInjectionEditor registers modules and constructors in the generated project's DI
+// root, in whichever DI style the project uses, which implementations detect from its files.
+// Every call is idempotent, and fails when the root or its options are missing.
 type DependencyInjectionEditor interface {
+	// Mode returns the project's DI style: DIFx, DIWire or DIManual.
+	Mode() string
+	// EnsureModule registers the generated module package importPath, imported as alias, the
+	// way the style expects: alias.Module() (fx), alias.ProviderSet and alias.Component (wire)
+	// or alias.Setup (manual).
+	EnsureModule(alias, importPath string) error
+	// Ensure adds optionExpr, importing importPath as alias, to the root's options.
 	Ensure(alias, importPath, optionExpr string) error
+	// EnsureEntry adds e to the options of its File and Func (the root's by default), or to
+	// their Into function. Styles other than fx take DIOption entries (and DIProvide ones with
+	// wire) without As, Group, Into, File or Func.
+	EnsureEntry(e DIEntry) error
 }
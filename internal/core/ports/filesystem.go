@@ -0,0 +1,15 @@
+package ports
+
+import "io/fs"
+
+// FileSystem is the file system every adapter reads and writes project files through.
+// Implementations include the OS file system and an in-memory one used for dry runs,
+// staged (all-or-nothing) generation, archives and tests.
+type FileSystem interface {
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	MkdirAll(path string, perm fs.FileMode) error
+	Stat(name string) (fs.FileInfo, error)
+	Remove(name string) error
+	ReadDir(name string) ([]fs.DirEntry, error)
+}
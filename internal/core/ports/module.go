@@ -1,5 +1,7 @@
 package ports
 
+import "io/fs"
+
 type Module interface {
 	Name() string  // machine-friendly, unique, e.g. "db:postgres"
 	Label() string // user-friendly, e.g. "PostgreSQL Adapter (pgx/sqlc)"
@@ -16,3 +18,51 @@ type Module interface {
 	// It should be safe to merge into existing config, setting only missing keys.
 	Defaults() map[string]any
 }
+
+// TemplateSource is implemented by modules that ship built-in templates.
+type TemplateSource interface {
+	// Templates returns the module's built-in template tree, rooted at its templates directory.
+	Templates() fs.FS
+}
+
+// HookStage is the point at which a module hook runs.
+type HookStage string
+
+const (
+	// HookPreApply and HookPostApply run in-process around the module's Apply, on the
+	// staged files; they support Go callbacks (Apply) only.
+	HookPreApply  HookStage = "pre-apply"
+	HookPostApply HookStage = "post-apply"
+	// HookPostGenerate runs after every file has been written to disk, through the PostHook port.
+	HookPostGenerate HookStage = "post-generate"
+)
+
+// Hook is a follow-up step declared by a module.
+type Hook struct {
+	Name  string
+	Stage HookStage
+
+	// Command is an external command for post-generate hooks, run in Dir (relative to the
+	// project root) with Env (KEY=VALUE) added to the environment.
+	Command []string
+	Dir     string
+	Env     []string
+
+	// Apply is a Go callback for pre-apply and post-apply hooks.
+	Apply func(ctx Ctx) error
+	// Run is a Go callback for post-generate hooks; dir is the hook's working directory.
+	Run func(dir string) (output string, err error)
+}
+
+// HookProvider is implemented by modules that declare hooks. Hooks is called around the
+// module's Apply; post-generate hooks are collected once all modules have been applied, so
+// they can depend on everything that was generated.
+type HookProvider interface {
+	Hooks(ctx Ctx) []Hook
+}
+
+// HookCollector is optionally implemented by a Ctx to receive the post-generate hooks of
+// applied modules, in application order.
+type HookCollector interface {
+	Defer(module string, h Hook)
+}
@@ -1,6 +1,25 @@
 package ports
 
 type PostHook interface {
-	// Run executes post-generation steps inside the target directory (e.g., go mod tidy, git init).
-	Run(targetDir string) error
+	// Run executes post-generation steps inside the target directory (e.g., go mod tidy, git init)
+	// in order and reports each one. It stops at the first failing step; the remaining steps are
+	// reported as skipped and the failure is returned.
+	Run(targetDir string) ([]StepResult, error)
+}
+
+// StepStatus is the outcome of a single hook step.
+type StepStatus string
+
+const (
+	StepOK      StepStatus = "ok"
+	StepFailed  StepStatus = "failed"
+	StepSkipped StepStatus = "skipped"
+)
+
+// StepResult reports what a hook step did, including its combined output.
+type StepResult struct {
+	Name   string
+	Status StepStatus
+	Output string
+	Err    error
 }
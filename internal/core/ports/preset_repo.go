@@ -0,0 +1,9 @@
+package ports
+
+import "github.com/nduyhai/gocraft/internal/core/entity"
+
+// PresetRepo provides the named presets available to `new --preset`.
+type PresetRepo interface {
+	List() []entity.Preset
+	Get(name string) (entity.Preset, error)
+}
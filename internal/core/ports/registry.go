@@ -4,5 +4,21 @@ type Registry interface {
 	Register(Module)
 	List() []Module
 	Get(name string) (Module, bool)
+	// Plan resolves the requested modules and their transitive requirements without applying them.
+	// Conflicts are reported in the plan; unknown modules and cycles are returned as errors.
+	Plan(names ...string) (Plan, error)
 	Apply(ctx Ctx, names ...string) error
 }
+
+// Plan is the resolved set of modules for a request, in application order.
+type Plan struct {
+	Requested []string   `json:"requested"`
+	Order     []string   `json:"order"` // requirements come before dependents
+	Conflicts []Conflict `json:"conflicts"`
+}
+
+// Conflict records that Module declares a conflict with With, and both are in the plan.
+type Conflict struct {
+	Module string `json:"module"`
+	With   string `json:"with"`
+}
@@ -1,19 +1,84 @@
 package ports
 
+import (
+	"io/fs"
+	"strings"
+)
+
+// DefaultsTemplate is the module-relative template holding a module's config defaults.
+// It is merged into the project config by the ConfigEditor and never rendered as a file.
+const DefaultsTemplate = "config/defaults.yml.tmpl"
+
+// SchemaTemplate is the module-relative JSON Schema describing a module's config section.
+// The ConfigEditor merges it into the project's config/schema.json; it is never rendered.
+const SchemaTemplate = "config/schema.json.tmpl"
+
+// DefaultsProfile reports whether path is a defaults template: DefaultsTemplate (profile "")
+// or config/defaults.<profile>.yml.tmpl, merged into the config.<profile>.yml overlay.
+func DefaultsProfile(path string) (profile string, ok bool) {
+	if path == DefaultsTemplate {
+		return "", true
+	}
+	rest, found := strings.CutPrefix(path, "config/defaults.")
+	if !found {
+		return "", false
+	}
+	profile, found = strings.CutSuffix(rest, ".yml.tmpl")
+	if !found || profile == "" || strings.ContainsAny(profile, "./") {
+		return "", false
+	}
+	return profile, true
+}
+
+// Kinds of template files. Only KindFile files are rendered; defaults and schema templates are
+// merged into the project config by the ConfigEditor.
+const (
+	KindFile     = ""
+	KindDefaults = "defaults"
+	KindSchema   = "schema"
+)
+
+// TemplateKind returns the kind of the module-relative template path.
+func TemplateKind(path string) string {
+	if _, ok := DefaultsProfile(path); ok {
+		return KindDefaults
+	}
+	if path == SchemaTemplate {
+		return KindSchema
+	}
+	return KindFile
+}
+
 // Template represents a named template repository entry that can be rendered.
 type Template struct {
 	Name  string // template name (e.g., "basic")
 	Files []TmplFile
 }
 
+// TmplFile is a single template entry.
+//
+// Files whose path ends in ".tmpl" are rendered with text/template and have the
+// suffix stripped; any other file (images, jars, descriptor sets, .gitkeep) is
+// copied verbatim. Content may therefore hold arbitrary bytes.
 type TmplFile struct {
 	Path    string
 	Content string
+	// Mode is the permission bits of the source file, when the source carries
+	// meaningful modes (e.g. an on-disk directory). Zero means "use the default".
+	Mode fs.FileMode
+	// Source tells where the file was loaded from (e.g. "builtin" or an override directory).
+	Source string
+	// Kind is KindFile, KindDefaults or KindSchema (see TemplateKind).
+	Kind string
 }
 
 type TemplateRepo interface {
-	// Load returns the template by name.
+	// Load returns the template by name (a module name for module templates), config
+	// defaults and schema included and marked by Kind.
 	Load(name string) (Template, error)
 	// Names lists available template names.
 	Names() []string
+	// FS returns the template tree of name with overrides applied; nil when the module ships
+	// no templates.
+	FS(name string) (fs.FS, error)
 }
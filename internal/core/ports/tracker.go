@@ -0,0 +1,7 @@
+package ports
+
+// ModuleTracker is optionally implemented by a Ctx to learn which module the registry is
+// about to apply, e.g. to attribute generated files to the module that wrote them.
+type ModuleTracker interface {
+	Enter(module string)
+}
@@ -14,6 +14,16 @@ func (uc ApplyModules) Execute(ctx ports.Ctx, names ...string) error {
 	if uc.Registry == nil {
 		return nil
 	}
+	// Templates render the variant of the project's DI style: an existing project's is
+	// detected from its DI root
+	if _, ok := ctx.Values()["di"]; !ok {
+		if ed := ctx.AdaptersModule(); ed != nil {
+			ctx.SetValue("di", ed.Mode())
+		}
+	}
+	if _, err := ports.DIMode(ctx.Values()); err != nil {
+		return err
+	}
 	return uc.Registry.Apply(ctx, names...)
 }
 
@@ -28,3 +38,16 @@ func (uc ListModules) Execute() []ports.Module {
 	}
 	return uc.Registry.List()
 }
+
+// PlanModules resolves the modules that applying names would touch, and their conflicts,
+// without generating anything.
+type PlanModules struct {
+	Registry ports.Registry
+}
+
+func (uc PlanModules) Execute(names ...string) (ports.Plan, error) {
+	if uc.Registry == nil {
+		return ports.Plan{Requested: names}, nil
+	}
+	return uc.Registry.Plan(names...)
+}
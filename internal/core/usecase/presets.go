@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/nduyhai/gocraft/internal/core/entity"
+	"github.com/nduyhai/gocraft/internal/core/ports"
+)
+
+// ListPresets returns every preset known to the PresetRepo port.
+type ListPresets struct {
+	Repo ports.PresetRepo
+}
+
+func (uc ListPresets) Execute() []entity.Preset {
+	if uc.Repo == nil {
+		return nil
+	}
+	return uc.Repo.List()
+}
+
+// LoadPreset loads a preset by name and checks that the registry knows its modules.
+type LoadPreset struct {
+	Repo     ports.PresetRepo
+	Registry ports.Registry
+}
+
+func (uc LoadPreset) Execute(name string) (entity.Preset, error) {
+	if uc.Repo == nil {
+		return entity.Preset{}, fmt.Errorf("unknown preset %q", name)
+	}
+	p, err := uc.Repo.Get(name)
+	if err != nil {
+		return entity.Preset{}, err
+	}
+	if uc.Registry != nil {
+		for _, m := range p.Modules {
+			if _, ok := uc.Registry.Get(m); !ok {
+				return entity.Preset{}, fmt.Errorf("preset %s (%s): unknown module %q", name, p.Source, m)
+			}
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"github.com/nduyhai/gocraft/internal/core/ports"
+)
+
+// ListTemplates loads every template known to the TemplateRepo port.
+type ListTemplates struct {
+	Repo ports.TemplateRepo
+}
+
+func (uc ListTemplates) Execute() ([]ports.Template, error) {
+	if uc.Repo == nil {
+		return nil, nil
+	}
+	var out []ports.Template
+	for _, name := range uc.Repo.Names() {
+		tpl, err := uc.Repo.Load(name)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, tpl)
+	}
+	return out, nil
+}
+
+// LoadTemplate loads a single template by name from the TemplateRepo port.
+type LoadTemplate struct {
+	Repo ports.TemplateRepo
+}
+
+func (uc LoadTemplate) Execute(name string) (ports.Template, error) {
+	if uc.Repo == nil {
+		return ports.Template{}, nil
+	}
+	return uc.Repo.Load(name)
+}
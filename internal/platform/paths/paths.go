@@ -0,0 +1,32 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ProjectDir is the per-project directory holding gocraft customizations.
+const ProjectDir = ".gocraft"
+
+// UserConfigDir returns the gocraft user configuration directory:
+// $XDG_CONFIG_HOME/gocraft when set, otherwise ~/.config/gocraft.
+func UserConfigDir() (string, error) {
+	if x := os.Getenv("XDG_CONFIG_HOME"); x != "" {
+		return filepath.Join(x, "gocraft"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "gocraft"), nil
+}
+
+// TemplateSearchDirs returns the template override roots in priority order:
+// <projectDir>/.gocraft/templates, then <user config>/templates.
+func TemplateSearchDirs(projectDir string) []string {
+	dirs := []string{filepath.Join(projectDir, ProjectDir, "templates")}
+	if u, err := UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(u, "templates"))
+	}
+	return dirs
+}
@@ -0,0 +1,108 @@
+package settings
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// keys lists the settings `config set` accepts besides values.<path>.
+var keys = map[string]string{
+	"module_prefix": "string",
+	"modules":       "list",
+	"templates":     "string",
+	"conflicts":     "string",
+	"versions":      "string",
+	"hooks.git":     "bool",
+	"hooks.tidy":    "bool",
+	"hooks.verify":  "bool",
+}
+
+// Set writes key=value into the settings file at path, creating it when missing and keeping
+// the rest of the file (including comments) as is. Lists are comma-separated; values.<path>
+// entries are parsed as YAML scalars.
+func Set(path, key, value string) error {
+	kind, ok := keys[key]
+	if !ok {
+		if !strings.HasPrefix(key, "values.") || strings.Contains(key, "..") || strings.HasSuffix(key, ".") {
+			return fmt.Errorf("unknown setting %q (hooks.run and presets are edited in the file)", key)
+		}
+		kind = "scalar"
+	}
+	val := &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+	switch kind {
+	case "string":
+		val.Tag = "!!str"
+	case "bool":
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%s must be true or false", key)
+		}
+		val.Tag = "!!bool"
+	case "list":
+		val = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				val.Content = append(val.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item})
+			}
+		}
+	}
+
+	var doc yaml.Node
+	b, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		if err := yaml.Unmarshal(b, &doc); err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if err := setPath(doc.Content[0], strings.Split(key, "."), val); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, out.Bytes(), 0o644)
+}
+
+func setPath(m *yaml.Node, path []string, val *yaml.Node) error {
+	if m.Kind != yaml.MappingNode {
+		return fmt.Errorf("cannot set a key inside a %s", m.ShortTag())
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			val.HeadComment, val.LineComment = m.Content[i+1].HeadComment, m.Content[i+1].LineComment
+			m.Content[i+1] = val
+			return nil
+		}
+		return setPath(m.Content[i+1], path[1:], val)
+	}
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: path[0]}
+	for _, p := range path[1:] {
+		child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		m.Content = append(m.Content, key, child)
+		m, key = child, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: p}
+	}
+	m.Content = append(m.Content, key, val)
+	return nil
+}
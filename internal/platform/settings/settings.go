@@ -0,0 +1,326 @@
+// Package settings loads gocraft's own configuration: the user file
+// (~/.config/gocraft/config.yaml) and the project file (.gocraft.yaml, searched upward from the
+// working directory). Project settings override user settings; command-line flags override both.
+package settings
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nduyhai/gocraft/internal/core/entity"
+	"github.com/nduyhai/gocraft/internal/platform/paths"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ProjectFile is the project-level settings file name.
+	ProjectFile = ".gocraft.yaml"
+	// UserFile is the settings file name inside the user config directory.
+	UserFile = "config.yaml"
+)
+
+// Settings are the defaults gocraft applies to new, add and service add.
+type Settings struct {
+	// ModulePrefix is prepended to the project name when -m is not given, e.g. github.com/ourorg.
+	ModulePrefix string `yaml:"module_prefix,omitempty"`
+	// Modules are applied in addition to --with.
+	Modules []string `yaml:"modules,omitempty"`
+	// Values are template values; --set overrides them key by key.
+	Values map[string]any `yaml:"values,omitempty"`
+	// Templates is a template override directory, relative to the file that sets it.
+	Templates string `yaml:"templates,omitempty"`
+	// Conflicts is the strategy for files that already exist: error, skip or overwrite.
+	Conflicts string `yaml:"conflicts,omitempty"`
+	// Versions is the go.mod version policy (see --versions).
+	Versions string `yaml:"versions,omitempty"`
+	Hooks    Hooks  `yaml:"hooks,omitempty"`
+	// Presets add to or replace the built-in presets by name.
+	Presets map[string]entity.Preset `yaml:"presets,omitempty"`
+}
+
+// Hooks are the post-generation defaults; nil booleans leave the flag default alone.
+type Hooks struct {
+	Git    *bool     `yaml:"git,omitempty"`
+	Tidy   *bool     `yaml:"tidy,omitempty"`
+	Verify *bool     `yaml:"verify,omitempty"`
+	Run    []Command `yaml:"run,omitempty"`
+}
+
+// Command is a post-generate command run after the modules' own hooks.
+type Command struct {
+	Name string   `yaml:"name"`
+	Run  []string `yaml:"run"`
+	Dir  string   `yaml:"dir,omitempty"`
+	Env  []string `yaml:"env,omitempty"`
+}
+
+// Layer is one settings file.
+type Layer struct {
+	Scope string // "user" or "project"
+	Path  string // may not exist yet
+	data  map[string]any
+	s     Settings
+}
+
+// Exists reports whether the layer's file was found.
+func (l Layer) Exists() bool { return l.data != nil }
+
+// Config is the user and project layers and their merged settings.
+type Config struct {
+	User    Layer
+	Project Layer
+	Settings
+}
+
+// UserPath returns the path of the user settings file.
+func UserPath() (string, error) {
+	dir, err := paths.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, UserFile), nil
+}
+
+// FindProject returns the nearest .gocraft.yaml in dir or its parents, or "" when there is none.
+func FindProject(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		p := filepath.Join(dir, ProjectFile)
+		if fi, err := os.Stat(p); err == nil && !fi.IsDir() {
+			return p
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Load reads the user settings and the project settings found from cwd and merges them.
+// When no project file exists, Project.Path is cwd/.gocraft.yaml.
+func Load(cwd string) (*Config, error) {
+	c := &Config{User: Layer{Scope: "user"}, Project: Layer{Scope: "project"}}
+	if p, err := UserPath(); err == nil {
+		c.User.Path = p
+		if err := c.User.read(); err != nil {
+			return nil, err
+		}
+	}
+	c.Project.Path = FindProject(cwd)
+	if c.Project.Path == "" {
+		abs, err := filepath.Abs(cwd)
+		if err != nil {
+			return nil, err
+		}
+		c.Project.Path = filepath.Join(abs, ProjectFile)
+	}
+	if err := c.Project.read(); err != nil {
+		return nil, err
+	}
+	c.Settings = merge(c.User.s, c.Project.s)
+	return c, nil
+}
+
+func (l *Layer) read() error {
+	b, err := os.ReadFile(l.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read %s settings: %w", l.Scope, err)
+	}
+	l.data = map[string]any{}
+	if err := yaml.Unmarshal(b, &l.data); err != nil {
+		return fmt.Errorf("parse %s: %w", l.Path, err)
+	}
+	if l.data == nil { // empty file
+		l.data = map[string]any{}
+	}
+	if err := yaml.Unmarshal(b, &l.s); err != nil {
+		return fmt.Errorf("parse %s: %w", l.Path, err)
+	}
+	for name, p := range l.s.Presets {
+		p.Name, p.Source = name, l.Scope
+		l.s.Presets[name] = p
+	}
+	if l.s.Templates != "" {
+		l.s.Templates = resolvePath(filepath.Dir(l.Path), l.s.Templates)
+	}
+	return nil
+}
+
+// resolvePath expands a leading ~/ and makes rel relative to dir.
+func resolvePath(dir, rel string) string {
+	if strings.HasPrefix(rel, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rel[2:])
+		}
+	}
+	if filepath.IsAbs(rel) {
+		return rel
+	}
+	return filepath.Join(dir, rel)
+}
+
+// merge overlays over on base: set fields replace, lists replace, values merge key by key.
+func merge(base, over Settings) Settings {
+	out := base
+	if over.ModulePrefix != "" {
+		out.ModulePrefix = over.ModulePrefix
+	}
+	if over.Modules != nil {
+		out.Modules = over.Modules
+	}
+	if over.Templates != "" {
+		out.Templates = over.Templates
+	}
+	if over.Conflicts != "" {
+		out.Conflicts = over.Conflicts
+	}
+	if over.Versions != "" {
+		out.Versions = over.Versions
+	}
+	if over.Hooks.Git != nil {
+		out.Hooks.Git = over.Hooks.Git
+	}
+	if over.Hooks.Tidy != nil {
+		out.Hooks.Tidy = over.Hooks.Tidy
+	}
+	if over.Hooks.Verify != nil {
+		out.Hooks.Verify = over.Hooks.Verify
+	}
+	if over.Hooks.Run != nil {
+		out.Hooks.Run = over.Hooks.Run
+	}
+	out.Presets = map[string]entity.Preset{}
+	for _, ps := range []map[string]entity.Preset{base.Presets, over.Presets} {
+		for name, p := range ps {
+			out.Presets[name] = p
+		}
+	}
+	out.Values = map[string]any{}
+	mergeValues(out.Values, base.Values)
+	mergeValues(out.Values, over.Values)
+	return out
+}
+
+func mergeValues(dst, src map[string]any) {
+	for k, v := range src {
+		sm, ok := v.(map[string]any)
+		if !ok {
+			dst[k] = v
+			continue
+		}
+		dm, ok := dst[k].(map[string]any)
+		if !ok {
+			dm = map[string]any{}
+			dst[k] = dm
+		}
+		mergeValues(dm, sm)
+	}
+}
+
+// Module returns the default module path for a project named name, or "" without a prefix.
+func (s Settings) Module(name string) string {
+	if s.ModulePrefix == "" {
+		return ""
+	}
+	return strings.TrimSuffix(s.ModulePrefix, "/") + "/" + name
+}
+
+// Entry is one flattened setting and the layer it comes from.
+type Entry struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// Entries returns the effective settings as dotted keys, sorted, with the layer that set each.
+func (c *Config) Entries() []Entry {
+	byKey := map[string]Entry{}
+	for _, l := range []Layer{c.User, c.Project} {
+		flat := map[string]string{}
+		flatten(flat, "", l.data)
+		for k, v := range flat {
+			byKey[k] = Entry{Key: k, Value: v, Source: l.Scope}
+		}
+	}
+	out := make([]Entry, 0, len(byKey))
+	for _, e := range byKey {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// Get returns the entries for key: the setting itself, or every setting below it.
+func (c *Config) Get(key string) []Entry {
+	var out []Entry
+	for _, e := range c.Entries() {
+		if e.Key == key || strings.HasPrefix(e.Key, key+".") {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// flatten writes maps as dotted keys; lists of scalars are comma-separated and other lists
+// are rendered as flow YAML so that a list is always a single setting.
+func flatten(out map[string]string, prefix string, v any) {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, sub := range t {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+			flatten(out, key, sub)
+		}
+	case []any:
+		parts := make([]string, 0, len(t))
+		for _, item := range t {
+			switch item.(type) {
+			case map[string]any, []any:
+				out[prefix] = flowYAML(t)
+				return
+			}
+			parts = append(parts, fmt.Sprint(item))
+		}
+		out[prefix] = strings.Join(parts, ",")
+	case nil:
+		if prefix != "" {
+			out[prefix] = ""
+		}
+	default:
+		out[prefix] = fmt.Sprint(t)
+	}
+}
+
+func flowYAML(v any) string {
+	var n yaml.Node
+	if err := n.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	setFlow(&n)
+	b, err := yaml.Marshal(&n)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSpace(string(b))
+}
+
+func setFlow(n *yaml.Node) {
+	n.Style |= yaml.FlowStyle
+	for _, c := range n.Content {
+		setFlow(c)
+	}
+}
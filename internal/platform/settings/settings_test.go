@@ -0,0 +1,97 @@
+package settings_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nduyhai/gocraft/internal/platform/settings"
+)
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadProjectOverridesUser(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	write(t, filepath.Join(home, "gocraft", settings.UserFile), `
+module_prefix: github.com/ourorg
+modules: [feature:makefile]
+values:
+  gorm: {driver: postgres, dsn: x}
+hooks: {git: true}
+`)
+	repo := t.TempDir()
+	write(t, filepath.Join(repo, settings.ProjectFile), `
+modules: [feature:gitignore]
+templates: tpl
+values:
+  gorm: {driver: mysql}
+`)
+	sub := filepath.Join(repo, "services", "billing")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := settings.Load(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Module("demo"); got != "github.com/ourorg/demo" {
+		t.Errorf("module = %q", got)
+	}
+	if strings.Join(cfg.Modules, ",") != "feature:gitignore" {
+		t.Errorf("modules = %v, want the project list", cfg.Modules)
+	}
+	gorm := cfg.Values["gorm"].(map[string]any)
+	if gorm["driver"] != "mysql" || gorm["dsn"] != "x" {
+		t.Errorf("values.gorm = %v, want merged with project winning", gorm)
+	}
+	if cfg.Hooks.Git == nil || !*cfg.Hooks.Git {
+		t.Errorf("hooks.git from the user file was lost")
+	}
+	if cfg.Templates != filepath.Join(repo, "tpl") {
+		t.Errorf("templates = %q, want it relative to the project file", cfg.Templates)
+	}
+
+	src := map[string]string{}
+	for _, e := range cfg.Entries() {
+		src[e.Key] = e.Source + "=" + e.Value
+	}
+	if src["values.gorm.driver"] != "project=mysql" || src["values.gorm.dsn"] != "user=x" || src["modules"] != "project=feature:gitignore" {
+		t.Errorf("entries = %v", src)
+	}
+}
+
+func TestSetKeepsComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), settings.ProjectFile)
+	write(t, path, "# team defaults\nvalues:\n  gorm:\n    driver: postgres # default\n")
+
+	for _, kv := range [][2]string{{"values.gorm.driver", "mysql"}, {"modules", "feature:makefile, feature:gitignore"}, {"hooks.tidy", "true"}} {
+		if err := settings.Set(path, kv[0], kv[1]); err != nil {
+			t.Fatalf("set %s: %v", kv[0], err)
+		}
+	}
+	b, _ := os.ReadFile(path)
+	out := string(b)
+	for _, want := range []string{"# team defaults", "driver: mysql # default", "- feature:gitignore", "tidy: true"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	if err := settings.Set(path, "hooks.run", "x"); err == nil {
+		t.Error("expected an error for hooks.run")
+	}
+	if err := settings.Set(path, "hooks.git", "yes"); err == nil {
+		t.Error("expected an error for a non-boolean hooks.git")
+	}
+}